@@ -0,0 +1,24 @@
+package auth
+
+import "context"
+
+// Identity es el llamante autenticado de una petición.
+type Identity struct {
+	Subject string
+	Issuer  string
+	Roles   []string
+}
+
+type identityKey struct{}
+
+// NewContext devuelve una copia de ctx con la identidad del llamante.
+func NewContext(ctx context.Context, identity *Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+// FromContext devuelve la identidad del llamante, si la petición está
+// autenticada.
+func FromContext(ctx context.Context) (*Identity, bool) {
+	identity, ok := ctx.Value(identityKey{}).(*Identity)
+	return identity, ok && identity != nil
+}
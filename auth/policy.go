@@ -0,0 +1,140 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Roles reconocidos por el servicio
+const (
+	RoleGuest   = "guest"
+	RoleHost    = "host"
+	RoleManager = "manager"
+	RoleAdmin   = "admin"
+)
+
+// Policy indica qué roles pueden invocar cada RPC. Las claves de Methods
+// son nombres completos ("/reservation.TableService/CreateTable") o un
+// servicio entero ("/reservation.TableService/*"). Los métodos no listados
+// usan Default. El rol admin puede invocarlo todo. Los métodos de Public se
+// pueden invocar sin token; si se envía uno, se valida igualmente.
+type Policy struct {
+	Default []string            `json:"default"`
+	Methods map[string][]string `json:"methods"`
+	Public  []string            `json:"public"`
+}
+
+var staffRoles = []string{RoleHost, RoleManager}
+var allRoles = []string{RoleGuest, RoleHost, RoleManager}
+
+// DefaultPolicy es la política usada si no se indica AUTH_POLICY_FILE.
+func DefaultPolicy() *Policy {
+	return &Policy{
+		Default: []string{RoleAdmin},
+		Methods: map[string][]string{
+			"/reservation.ReservationService/CreateReservation":       allRoles,
+			"/reservation.ReservationService/GetReservationByID":      allRoles,
+			"/reservation.ReservationService/GetReservationsByUserID": allRoles,
+			"/reservation.ReservationService/GetReservationsByDate":   staffRoles,
+			"/reservation.ReservationService/SearchReservations":      staffRoles,
+			"/reservation.ReservationService/UpdateReservation":       allRoles,
+			"/reservation.ReservationService/DeleteReservation":       allRoles,
+			"/reservation.ReservationService/RestoreReservation":      staffRoles,
+			"/reservation.ReservationService/GetReservationHistory":   allRoles,
+			"/reservation.ReservationService/GetGuestReliability":     allRoles,
+			"/reservation.ReservationService/ConfirmDepositPayment":   allRoles,
+			"/reservation.ReservationService/WalkIn":                  staffRoles,
+			"/reservation.ReservationService/HoldSlot":                allRoles,
+			"/reservation.ReservationService/CreateReservationSeries": allRoles,
+			"/reservation.ReservationService/UpdateReservationSeries": allRoles,
+			"/reservation.ReservationService/CancelReservationSeries": allRoles,
+			"/reservation.TableService/CreateTable":                   {RoleManager},
+			"/reservation.TableService/UpdateTable":                   {RoleManager},
+			"/reservation.TableService/GetTables":                     allRoles,
+			"/reservation.TableService/GetAvailableTables":            allRoles,
+			"/reservation.WaitlistService/JoinWaitlist":               allRoles,
+			"/reservation.WaitlistService/GetWaitlist":                staffRoles,
+			"/reservation.WaitlistService/AcceptWaitlistOffer":        allRoles,
+			"/reservation.WaitlistService/LeaveWaitlist":              allRoles,
+			"/reservation.RestaurantService/GetRestaurants":           allRoles,
+			"/reservation.RestaurantService/GetRestaurantByID":        allRoles,
+			"/reservation.AuditService/GetAuditHistory":               {RoleManager},
+			"/reservation.WebhookService/RegisterWebhook":             {RoleManager},
+			"/reservation.WebhookService/ListWebhooks":                {RoleManager},
+			"/reservation.WebhookService/DeleteWebhook":               {RoleManager},
+			"/reservation.WebhookService/GetWebhookDeliveries":        {RoleManager},
+			"/reservation.GuestService/GetGuestProfile":               allRoles,
+			"/reservation.GuestService/UpdateGuestProfile":            allRoles,
+		},
+		Public: []string{
+			"/reservation.ReservationService/GetReservationByCode",
+			"/reservation.ReservationService/UpdateReservationByCode",
+			"/reservation.ReservationService/CancelReservationByCode",
+		},
+	}
+}
+
+// LoadPolicy lee la política desde AUTH_POLICY_FILE (JSON) o devuelve la
+// política por defecto si la variable no está definida.
+func LoadPolicy() (*Policy, error) {
+	path := os.Getenv("AUTH_POLICY_FILE")
+	if path == "" {
+		return DefaultPolicy(), nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy file: %w", err)
+	}
+	var policy Policy
+	if err := json.Unmarshal(data, &policy); err != nil {
+		return nil, fmt.Errorf("failed to parse policy file: %w", err)
+	}
+	return &policy, nil
+}
+
+// IsPublic indica si el método se puede invocar sin autenticar.
+func (p *Policy) IsPublic(method string) bool {
+	for _, public := range p.Public {
+		if public == method {
+			return true
+		}
+	}
+	return false
+}
+
+// Allowed indica si alguno de los roles puede invocar el método.
+func (p *Policy) Allowed(method string, roles []string) bool {
+	allowed, ok := p.Methods[method]
+	if !ok {
+		service := method[:strings.LastIndex(method, "/")+1]
+		allowed, ok = p.Methods[service+"*"]
+	}
+	if !ok {
+		allowed = p.Default
+	}
+
+	for _, role := range roles {
+		if role == RoleAdmin {
+			return true
+		}
+		for _, a := range allowed {
+			if role == a {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// IsStaff indica si la identidad tiene algún rol de personal. Los usuarios
+// que solo son huéspedes únicamente pueden ver y modificar sus reservas.
+func (i *Identity) IsStaff() bool {
+	for _, role := range i.Roles {
+		if role == RoleHost || role == RoleManager || role == RoleAdmin {
+			return true
+		}
+	}
+	return false
+}
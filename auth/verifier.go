@@ -0,0 +1,170 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Verifier valida tokens JWT firmados con HS256 (secreto compartido) o con
+// RS256 (claves públicas de un fichero JWKS).
+type Verifier struct {
+	hmacSecret []byte
+	rsaKeys    map[string]*rsa.PublicKey
+	issuer     string
+	audience   string
+}
+
+// LoadVerifierFromEnv construye el verificador a partir de:
+//
+//	JWT_HS256_SECRET  secreto para tokens HS256
+//	JWT_JWKS_FILE     fichero JWKS con las claves públicas RS256
+//	JWT_ISSUER        emisor esperado (opcional)
+//	JWT_AUDIENCE      audiencia esperada (opcional)
+//
+// Devuelve nil si no hay ninguna clave configurada.
+func LoadVerifierFromEnv() (*Verifier, error) {
+	v := &Verifier{
+		issuer:   os.Getenv("JWT_ISSUER"),
+		audience: os.Getenv("JWT_AUDIENCE"),
+	}
+	if secret := os.Getenv("JWT_HS256_SECRET"); secret != "" {
+		v.hmacSecret = []byte(secret)
+	}
+	if path := os.Getenv("JWT_JWKS_FILE"); path != "" {
+		keys, err := loadJWKS(path)
+		if err != nil {
+			return nil, err
+		}
+		v.rsaKeys = keys
+	}
+	if v.hmacSecret == nil && len(v.rsaKeys) == 0 {
+		return nil, nil
+	}
+	return v, nil
+}
+
+// Verify comprueba la firma, la expiración y, si están configurados, el
+// emisor y la audiencia del token, y devuelve la identidad que contiene.
+func (v *Verifier) Verify(tokenString string) (*Identity, error) {
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{"HS256", "RS256"}),
+		jwt.WithExpirationRequired(),
+	}
+	if v.issuer != "" {
+		opts = append(opts, jwt.WithIssuer(v.issuer))
+	}
+	if v.audience != "" {
+		opts = append(opts, jwt.WithAudience(v.audience))
+	}
+
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, v.keyFunc, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	subject, err := claims.GetSubject()
+	if err != nil || subject == "" {
+		return nil, fmt.Errorf("token has no subject")
+	}
+	issuer, _ := claims.GetIssuer()
+
+	return &Identity{
+		Subject: subject,
+		Issuer:  issuer,
+		Roles:   rolesFromClaims(claims),
+	}, nil
+}
+
+func (v *Verifier) keyFunc(token *jwt.Token) (interface{}, error) {
+	switch token.Method.Alg() {
+	case "HS256":
+		if v.hmacSecret == nil {
+			return nil, fmt.Errorf("HS256 tokens are not accepted")
+		}
+		return v.hmacSecret, nil
+	case "RS256":
+		if len(v.rsaKeys) == 0 {
+			return nil, fmt.Errorf("RS256 tokens are not accepted")
+		}
+		kid, _ := token.Header["kid"].(string)
+		if key, ok := v.rsaKeys[kid]; ok {
+			return key, nil
+		}
+		// Un JWKS con una sola clave admite tokens sin kid
+		if kid == "" && len(v.rsaKeys) == 1 {
+			for _, key := range v.rsaKeys {
+				return key, nil
+			}
+		}
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	}
+	return nil, fmt.Errorf("unexpected signing method %s", token.Method.Alg())
+}
+
+// rolesFromClaims admite tanto "roles" (lista) como "role" (cadena).
+func rolesFromClaims(claims jwt.MapClaims) []string {
+	var roles []string
+	if list, ok := claims["roles"].([]interface{}); ok {
+		for _, role := range list {
+			if r, ok := role.(string); ok {
+				roles = append(roles, r)
+			}
+		}
+	}
+	if role, ok := claims["role"].(string); ok && role != "" {
+		roles = append(roles, role)
+	}
+	return roles
+}
+
+type jwks struct {
+	Keys []struct {
+		Kty string `json:"kty"`
+		Kid string `json:"kid"`
+		Use string `json:"use"`
+		Alg string `json:"alg"`
+		N   string `json:"n"`
+		E   string `json:"e"`
+	} `json:"keys"`
+}
+
+func loadJWKS(path string) (map[string]*rsa.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JWKS file: %w", err)
+	}
+	var set jwks
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("failed to parse JWKS file: %w", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey)
+	for _, key := range set.Keys {
+		if key.Kty != "RSA" || (key.Use != "" && key.Use != "sig") {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(key.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid modulus for key %q", key.Kid)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(key.E)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exponent for key %q", key.Kid)
+		}
+		keys[key.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("JWKS file has no RSA signing keys")
+	}
+	return keys, nil
+}
@@ -0,0 +1,174 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"ms-reservas/auth"
+	"ms-reservas/logging"
+	m "ms-reservas/models"
+	pb "ms-reservas/protos_pb/proto"
+	"ms-reservas/tracing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"google.golang.org/grpc"
+)
+
+// Entidades auditadas
+const (
+	AuditEntityReservation = "reservation"
+	AuditEntityTable       = "table"
+)
+
+// Acciones auditadas
+const (
+	AuditCreate  = "create"
+	AuditUpdate  = "update"
+	AuditDelete  = "delete"
+	AuditRestore = "restore"
+	AuditPurge   = "purge"
+)
+
+// actorSystem identifica los cambios hechos por procesos internos (p. ej.
+// el barrido de la lista de espera) o con la autenticación desactivada.
+const actorSystem = "system"
+
+const defaultAuditLimit = 100
+
+// EnsureAuditIndexes crea el índice usado para consultar el historial de
+// una entidad.
+func EnsureAuditIndexes(ctx context.Context) error {
+	collection := mongoClient.Database("reservations-db").Collection("audit_log")
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "entitytype", Value: 1}, {Key: "entityid", Value: 1}, {Key: "createat", Value: 1}},
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to create audit indexes", "error", err)
+	}
+	return err
+}
+
+// recordAudit añade una entrada al registro de auditoría. La colección es de
+// solo inserción: ningún código actualiza ni borra entradas. Un fallo al
+// auditar se registra pero no deshace el cambio, que ya está aplicado.
+func recordAudit(ctx context.Context, entityType, entityID, restaurantID, action string, before, after interface{}) {
+	entry := m.AuditEntry{
+		EntityType:   entityType,
+		EntityId:     entityID,
+		RestaurantId: restaurantID,
+		Action:       action,
+		Actor:        actorFromContext(ctx),
+		RequestId:    logging.RequestID(ctx),
+		CreateAt:     time.Now(),
+	}
+	if method, ok := grpc.Method(ctx); ok {
+		entry.Rpc = method
+	}
+
+	var err error
+	if entry.Before, err = auditSnapshot(before); err != nil {
+		slog.ErrorContext(ctx, "failed to encode audit snapshot", "error", err)
+	}
+	if entry.After, err = auditSnapshot(after); err != nil {
+		slog.ErrorContext(ctx, "failed to encode audit snapshot", "error", err)
+	}
+
+	collection := mongoClient.Database("reservations-db").Collection("audit_log")
+	if _, err = collection.InsertOne(ctx, entry); err != nil {
+		slog.ErrorContext(ctx, "failed to insert audit entry", "entity_type", entityType, "entity_id", entityID, "error", err)
+	}
+}
+
+// actorFromContext devuelve el sujeto autenticado de la petición o
+// actorSystem si no lo hay.
+func actorFromContext(ctx context.Context) string {
+	if identity, ok := auth.FromContext(ctx); ok {
+		return identity.Subject
+	}
+	return actorSystem
+}
+
+func auditSnapshot(doc interface{}) (bson.Raw, error) {
+	if doc == nil {
+		return nil, nil
+	}
+	return bson.Marshal(doc)
+}
+
+// GET AUDIT HISTORY
+func GetAuditHistoryHandler(ctx context.Context, req *pb.GetAuditHistoryRequest) (*pb.AuditHistory, error) {
+	ctx, span := tracing.Start(ctx, "controllers.GetAuditHistoryHandler")
+	defer span.End()
+
+	entries, err := GetAuditHistory(ctx, req.EntityType, req.EntityId, int64(req.Limit))
+	if err != nil {
+		return nil, err
+	}
+	var pbEntries []*pb.AuditEntry
+	for _, entry := range entries {
+		pbEntries = append(pbEntries, toPbAuditEntry(entry))
+	}
+	return &pb.AuditHistory{Entries: pbEntries}, nil
+}
+
+// GetAuditHistory devuelve los cambios de una entidad, del más antiguo al
+// más reciente.
+func GetAuditHistory(ctx context.Context, entityType, entityID string, limit int64) ([]m.AuditEntry, error) {
+	ctx, span := tracing.Start(ctx, "controllers.GetAuditHistory")
+	defer span.End()
+
+	if entityType != AuditEntityReservation && entityType != AuditEntityTable {
+		return nil, fmt.Errorf("invalid entity type, expected %s or %s", AuditEntityReservation, AuditEntityTable)
+	}
+	if entityID == "" {
+		return nil, fmt.Errorf("entityID is required")
+	}
+	if limit <= 0 {
+		limit = defaultAuditLimit
+	}
+
+	collection := mongoClient.Database("reservations-db").Collection("audit_log")
+	opts := options.Find().SetSort(bson.D{{Key: "createat", Value: 1}}).SetLimit(limit)
+	cursor, err := collection.Find(ctx, bson.M{"entitytype": entityType, "entityid": entityID}, opts)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to find audit entries", "error", err)
+		return nil, err
+	}
+	var entries []m.AuditEntry
+	if err = cursor.All(ctx, &entries); err != nil {
+		slog.ErrorContext(ctx, "failed to decode audit entries", "error", err)
+		return nil, err
+	}
+	return entries, nil
+}
+
+func toPbAuditEntry(entry m.AuditEntry) *pb.AuditEntry {
+	return &pb.AuditEntry{
+		Id:           entry.ID,
+		EntityType:   entry.EntityType,
+		EntityId:     entry.EntityId,
+		RestaurantId: entry.RestaurantId,
+		Action:       entry.Action,
+		Actor:        entry.Actor,
+		Rpc:          entry.Rpc,
+		RequestId:    entry.RequestId,
+		BeforeJson:   auditSnapshotJSON(entry.Before),
+		AfterJson:    auditSnapshotJSON(entry.After),
+		CreatedAt:    entry.CreateAt.Format(time.RFC3339),
+	}
+}
+
+func auditSnapshotJSON(raw bson.Raw) string {
+	if len(raw) == 0 {
+		return ""
+	}
+	data, err := bson.MarshalExtJSON(raw, false, false)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
@@ -0,0 +1,162 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"ms-reservas/auth"
+	m "ms-reservas/models"
+	pb "ms-reservas/protos_pb/proto"
+	"ms-reservas/tracing"
+)
+
+// Políticas que puede aplicar una cancelación
+const (
+	CancellationPolicyFree = "free"
+	CancellationPolicyLate = "late"
+	// CancellationPolicyEventOverride es la cancelación por el personal de
+	// una reserva en un evento que el cliente no puede cancelar.
+	CancellationPolicyEventOverride = "special_event_override"
+)
+
+// ErrNotCancellable indica que la política del restaurante no permite al
+// cliente cancelar la reserva.
+var ErrNotCancellable = errors.New("reservation cannot be cancelled")
+
+// lateCancellationWindow lee de LATE_CANCELLATION_HOURS la antelación
+// mínima para cancelar sin que cuente como tardía, para los restaurantes
+// que no la definen.
+func lateCancellationWindow() time.Duration {
+	return envHours("LATE_CANCELLATION_HOURS", 24*time.Hour)
+}
+
+// guestCancelling indica si quien cancela es el propio cliente, con su
+// token o con el código de la reserva.
+func guestCancelling(ctx context.Context, reservation m.Reservation) bool {
+	return actorFromContext(ctx) == reservation.UserId
+}
+
+// evaluateCancellation aplica la política de cancelación del restaurante a
+// la reserva. Devuelve ErrNotCancellable si el cliente intenta cancelar una
+// reserva de un evento no cancelable; el personal sí puede hacerlo. Solo la
+// cancelación del cliente puede ser tardía: la del personal o del sistema
+// se reembolsa íntegra.
+func evaluateCancellation(ctx context.Context, reservation m.Reservation) (*m.CancellationOutcome, error) {
+	ctx, span := tracing.Start(ctx, "controllers.evaluateCancellation")
+	defer span.End()
+
+	slot, restaurant, err := reservationSlot(ctx, reservation)
+	if err != nil {
+		return nil, err
+	}
+	policy := restaurant.Policies.Cancellation
+	now := time.Now()
+	outcome := &m.CancellationOutcome{
+		Policy:                CancellationPolicyFree,
+		FreeCancellationHours: policy.FreeCancellationHours,
+		CancelledAt:           now,
+		CancelledBy:           actorFromContext(ctx),
+	}
+
+	for _, event := range policy.NonCancellableEvents {
+		if event.Date != reservation.ReservationDate {
+			continue
+		}
+		if identity, ok := auth.FromContext(ctx); ok && !identity.IsStaff() {
+			return nil, fmt.Errorf("%w: %s does not allow cancellations", ErrNotCancellable, event.Name)
+		}
+		outcome.Policy = CancellationPolicyEventOverride
+	}
+
+	window := time.Duration(policy.FreeCancellationHours) * time.Hour
+	if window == 0 {
+		window = lateCancellationWindow()
+	}
+	if slot.Sub(now) < window && guestCancelling(ctx, reservation) {
+		outcome.Late = true
+		outcome.PenaltyCents = policy.LatePenaltyCents
+		if outcome.Policy == CancellationPolicyFree {
+			outcome.Policy = CancellationPolicyLate
+		}
+	}
+	return outcome, nil
+}
+
+// checkGuestReschedule aplica la política de cancelación cuando el cliente
+// cambia la fecha o la hora: mover la reserva equivale a cancelarla y
+// volver a reservar, así que no puede hacerlo en un evento no cancelable ni
+// dentro del plazo de cancelación tardía. El personal sí puede.
+func checkGuestReschedule(ctx context.Context, reservation m.Reservation) error {
+	if identity, ok := auth.FromContext(ctx); !ok || identity.IsStaff() {
+		return nil
+	}
+	outcome, err := evaluateCancellation(ctx, reservation)
+	if errors.Is(err, ErrNotCancellable) {
+		return ErrNotModifiable
+	}
+	if err != nil {
+		return err
+	}
+	if outcome.Late {
+		return ErrNotModifiable
+	}
+	return nil
+}
+
+func validateCancellationPolicy(policy m.CancellationPolicy) error {
+	if policy.FreeCancellationHours < 0 || policy.LatePenaltyCents < 0 {
+		return fmt.Errorf("cancellation policy cannot be negative")
+	}
+	for _, event := range policy.NonCancellableEvents {
+		if event.Name == "" {
+			return fmt.Errorf("special event name is required")
+		}
+		if _, err := time.Parse("02-01-2006", event.Date); err != nil {
+			return fmt.Errorf("invalid special event date format, expected dd-mm-yyyy")
+		}
+	}
+	return nil
+}
+
+func toPbCancellationPolicy(policy m.CancellationPolicy) *pb.CancellationPolicy {
+	var events []*pb.SpecialEvent
+	for _, event := range policy.NonCancellableEvents {
+		events = append(events, &pb.SpecialEvent{Name: event.Name, Date: event.Date})
+	}
+	return &pb.CancellationPolicy{
+		FreeCancellationHours: int32(policy.FreeCancellationHours),
+		LatePenaltyCents:      int32(policy.LatePenaltyCents),
+		NonCancellableEvents:  events,
+	}
+}
+
+func fromPbCancellationPolicy(policy *pb.CancellationPolicy) m.CancellationPolicy {
+	if policy == nil {
+		return m.CancellationPolicy{}
+	}
+	var events []m.SpecialEvent
+	for _, event := range policy.NonCancellableEvents {
+		events = append(events, m.SpecialEvent{Name: event.Name, Date: event.Date})
+	}
+	return m.CancellationPolicy{
+		FreeCancellationHours: int(policy.FreeCancellationHours),
+		LatePenaltyCents:      int(policy.LatePenaltyCents),
+		NonCancellableEvents:  events,
+	}
+}
+
+func toPbCancellationOutcome(outcome *m.CancellationOutcome) *pb.CancellationOutcome {
+	if outcome == nil {
+		return nil
+	}
+	return &pb.CancellationOutcome{
+		Policy:                outcome.Policy,
+		FreeCancellationHours: int32(outcome.FreeCancellationHours),
+		Late:                  outcome.Late,
+		PenaltyCents:          int32(outcome.PenaltyCents),
+		CancelledAt:           outcome.CancelledAt.Format(time.RFC3339),
+		CancelledBy:           outcome.CancelledBy,
+	}
+}
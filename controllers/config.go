@@ -0,0 +1,35 @@
+package controllers
+
+import (
+	"os"
+	"strconv"
+	"time"
+)
+
+// envMinutes lee una duración en minutos desde la variable de entorno key.
+// Si no está definida o no es válida, devuelve def.
+func envMinutes(key string, def time.Duration) time.Duration {
+	minutes, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || minutes <= 0 {
+		return def
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+// envDays es como envMinutes, pero con la duración expresada en días.
+func envDays(key string, def time.Duration) time.Duration {
+	days, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || days <= 0 {
+		return def
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// envHours es como envMinutes, pero con la duración expresada en horas.
+func envHours(key string, def time.Duration) time.Duration {
+	hours, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || hours <= 0 {
+		return def
+	}
+	return time.Duration(hours) * time.Hour
+}
@@ -0,0 +1,386 @@
+package controllers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"ms-reservas/auth"
+	"ms-reservas/events"
+	m "ms-reservas/models"
+	"ms-reservas/payments"
+	pb "ms-reservas/protos_pb/proto"
+	"ms-reservas/tracing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Estados del depósito de una reserva
+const (
+	DepositPending  = "pendiente"
+	DepositPaid     = "pagado"
+	DepositExpired  = "expirado"
+	DepositRefunded = "reembolsado"
+	// DepositRetained indica que la penalización de la cancelación se quedó
+	// con todo el depósito.
+	DepositRetained = "retenido"
+)
+
+// Jobs de los depósitos
+const (
+	JobReservationPaymentExpiry = "reservation_payment_expiry"
+	JobDepositRefund            = "deposit_refund"
+)
+
+// Errores de los depósitos. ErrDepositNotPaid es definitivo para el pago
+// consultado (pendiente, fallido o desconocido para el proveedor);
+// ErrPaymentProvider indica que no se pudo consultar y conviene reintentar.
+var (
+	ErrDepositNotPaid      = errors.New("deposit payment has not been completed")
+	ErrNotPendingPayment   = errors.New("reservation is not pending payment")
+	ErrDepositsUnavailable = errors.New("deposits are not available")
+	ErrPaymentProvider     = errors.New("payment provider unavailable")
+)
+
+// paymentProvider cobra y devuelve los depósitos. Sin proveedor no se
+// aceptan reservas que requieran depósito.
+var paymentProvider payments.PaymentProvider
+
+func SetPaymentProvider(provider payments.PaymentProvider) {
+	paymentProvider = provider
+}
+
+// paymentWindow es el plazo para pagar el depósito: el del restaurante o,
+// si no lo define, PAYMENT_WINDOW_MINUTES.
+func paymentWindow(restaurant *m.Restaurant) time.Duration {
+	if minutes := restaurant.Policies.Deposit.PaymentWindowMinutes; minutes > 0 {
+		return time.Duration(minutes) * time.Minute
+	}
+	return envMinutes("PAYMENT_WINDOW_MINUTES", 30*time.Minute)
+}
+
+// requiredDeposit devuelve el depósito que exige el restaurante para la
+// reserva, o 0 si no hace falta.
+func requiredDeposit(restaurant *m.Restaurant, reservationDate string, guestCount int) int {
+	policy := restaurant.Policies.Deposit
+	if policy.AmountPerGuestCents <= 0 {
+		return 0
+	}
+	large := policy.MinGuestCount > 0 && guestCount >= policy.MinGuestCount
+	special := slices.ContainsFunc(policy.Events, func(event m.SpecialEvent) bool {
+		return event.Date == reservationDate
+	})
+	if !large && !special {
+		return 0
+	}
+	return policy.AmountPerGuestCents * guestCount
+}
+
+// requestDeposit crea en el proveedor el pago del depósito y deja la
+// reserva pendiente de pago.
+func requestDeposit(ctx context.Context, restaurant *m.Restaurant, reservation *m.Reservation, amountCents int) error {
+	ctx, span := tracing.Start(ctx, "controllers.requestDeposit")
+	defer span.End()
+
+	if paymentProvider == nil {
+		return fmt.Errorf("deposits are not available, please contact the restaurant")
+	}
+	buf := make([]byte, 12)
+	if _, err := rand.Read(buf); err != nil {
+		return err
+	}
+	currency := restaurant.Policies.Deposit.Currency
+	payment, err := paymentProvider.CreatePayment(ctx, payments.PaymentRequest{
+		Reference:   "dep_" + hex.EncodeToString(buf),
+		AmountCents: amountCents,
+		Currency:    currency,
+		Description: fmt.Sprintf("%s %s %s", restaurant.Name, reservation.ReservationDate, reservation.ReservationTime),
+		Metadata:    map[string]string{"restaurant_id": restaurant.ID, "user_id": reservation.UserId},
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to create deposit payment", "error", err)
+		return err
+	}
+	reservation.Status = StatusPendingPayment
+	reservation.Deposit = &m.Deposit{
+		AmountCents: amountCents,
+		Currency:    currency,
+		PaymentId:   payment.ID,
+		CheckoutUrl: payment.CheckoutURL,
+		Status:      DepositPending,
+		ExpiresAt:   time.Now().Add(paymentWindow(restaurant)),
+	}
+	return nil
+}
+
+// CONFIRM DEPOSIT
+func ConfirmDepositPaymentHandler(ctx context.Context, req *pb.GetReservationByIDRequest) (*pb.Response, error) {
+	ctx, span := tracing.Start(ctx, "controllers.ConfirmDepositPaymentHandler")
+	defer span.End()
+
+	if err := ConfirmDepositPayment(ctx, req.RestaurantId, req.Id); err != nil {
+		return &pb.Response{Message: err.Error(), Success: false}, err
+	}
+	return &pb.Response{Message: "Deposit paid, reservation confirmed", Success: true}, nil
+}
+
+// ConfirmDepositPayment consulta el pago en el proveedor y, si se completó,
+// confirma la reserva.
+func ConfirmDepositPayment(ctx context.Context, restaurantID, id string) error {
+	ctx, span := tracing.Start(ctx, "controllers.ConfirmDepositPayment")
+	defer span.End()
+
+	reservation, err := GetReservationByID(ctx, restaurantID, id)
+	if err != nil {
+		return err
+	}
+	if reservation.Status != StatusPendingPayment || reservation.Deposit == nil {
+		return ErrNotPendingPayment
+	}
+	if paymentProvider == nil {
+		return ErrDepositsUnavailable
+	}
+	payment, err := paymentProvider.GetPayment(ctx, reservation.Deposit.PaymentId)
+	if errors.Is(err, payments.ErrPaymentNotFound) {
+		return fmt.Errorf("%w: %w", ErrDepositNotPaid, err)
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to get deposit payment", "error", err)
+		return fmt.Errorf("%w: %w", ErrPaymentProvider, err)
+	}
+	if payment.Status != payments.StatusSucceeded {
+		return fmt.Errorf("%w: payment is %s", ErrDepositNotPaid, payment.Status)
+	}
+
+	now := time.Now()
+	update := bson.M{"status": "confirmada", "deposit.status": DepositPaid, "deposit.paidat": now, "updateat": now}
+	if err = UpdateReservation(ctx, restaurantID, id, update, reservation.Version); err != nil {
+		return err
+	}
+	reservation.Status = "confirmada"
+	scheduleReservationJobs(ctx, *reservation)
+	return nil
+}
+
+// expireUnpaidReservation cancela la reserva cuyo depósito no se pagó a
+// tiempo. Antes comprueba el pago por si se completó sin confirmarse; si el
+// proveedor no responde, el job se reintenta en vez de cancelar una
+// reserva que quizá está pagada.
+func expireUnpaidReservation(ctx context.Context, job m.Job) error {
+	reservation, err := jobReservation(ctx, job)
+	if err != nil || reservation == nil || reservation.Status != StatusPendingPayment {
+		return err
+	}
+	err = ConfirmDepositPayment(ctx, reservation.RestaurantId, reservation.ID)
+	if err == nil || errors.Is(err, ErrNotPendingPayment) {
+		return nil
+	}
+	if !errors.Is(err, ErrDepositNotPaid) && !errors.Is(err, ErrDepositsUnavailable) {
+		return err
+	}
+	if paymentProvider != nil {
+		if err = paymentProvider.CancelPayment(ctx, reservation.Deposit.PaymentId); err != nil {
+			slog.WarnContext(ctx, "failed to cancel deposit payment", "reservation_id", reservation.ID, "error", err)
+		}
+	}
+	update := bson.M{"status": "cancelada", "deposit.status": DepositExpired, "updateat": time.Now()}
+	return UpdateReservation(ctx, reservation.RestaurantId, reservation.ID, update, reservation.Version)
+}
+
+// reviewGuestDeposit vuelve a calcular el depósito cuando el cliente cambia
+// la fecha o el número de comensales de su reserva, para que no pueda
+// reservar sin depósito y ampliarla después. Si el cambio exige un depósito
+// que la reserva no tiene, se pide y update deja la reserva pendiente de
+// pago; devuelve ese depósito para anularlo si el cambio no llega a
+// guardarse. Los cambios que piden otro importe sobre un depósito ya pedido
+// o pagado se rechazan. El personal no está sujeto a la política.
+func reviewGuestDeposit(ctx context.Context, before m.Reservation, update bson.M) (*m.Deposit, error) {
+	if identity, ok := auth.FromContext(ctx); !ok || identity.IsStaff() {
+		return nil, nil
+	}
+	date, guestCount := before.ReservationDate, before.GuestCount
+	if value, ok := update["reservationdate"].(string); ok {
+		date = value
+	}
+	if value, ok := update["guestcount"].(int); ok {
+		guestCount = value
+	}
+	if date == before.ReservationDate && guestCount == before.GuestCount {
+		return nil, nil
+	}
+
+	restaurant, err := GetRestaurantByID(ctx, before.RestaurantId)
+	if err != nil {
+		return nil, err
+	}
+	required := requiredDeposit(restaurant, date, guestCount)
+	if deposit := before.Deposit; deposit != nil && (deposit.Status == DepositPending || deposit.Status == DepositPaid) {
+		if deposit.Status == DepositPaid && required <= deposit.AmountCents {
+			return nil, nil
+		}
+		if deposit.Status == DepositPending && required == deposit.AmountCents {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("%w: the change needs a different deposit", ErrNotModifiable)
+	}
+	if required == 0 || !awaitingGuest(before.Status) {
+		return nil, nil
+	}
+
+	changed := before
+	changed.ReservationDate, changed.GuestCount = date, guestCount
+	if err = requestDeposit(ctx, restaurant, &changed, required); err != nil {
+		return nil, err
+	}
+	update["status"] = changed.Status
+	update["deposit"] = changed.Deposit
+	return changed.Deposit, nil
+}
+
+// settleDeposit resuelve el depósito de una reserva que se acaba de
+// cancelar o borrar: anula el pago si estaba pendiente y, si estaba pagado,
+// programa su devolución.
+func settleDeposit(ctx context.Context, reservation m.Reservation) {
+	if reservation.Deposit == nil {
+		return
+	}
+	switch reservation.Deposit.Status {
+	case DepositPending:
+		if paymentProvider == nil {
+			return
+		}
+		if err := paymentProvider.CancelPayment(ctx, reservation.Deposit.PaymentId); err != nil {
+			slog.WarnContext(ctx, "failed to cancel deposit payment", "reservation_id", reservation.ID, "error", err)
+		}
+	case DepositPaid:
+		data := map[string]string{"restaurant_id": reservation.RestaurantId, "reservation_id": reservation.ID}
+		jobRunner.Schedule(ctx, JobDepositRefund, reservationJobKey(JobDepositRefund, reservation.ID), time.Now(), data)
+	}
+}
+
+// depositRefund es lo que se devuelve del depósito: todo salvo la
+// penalización que fijó la política de cancelación.
+func depositRefund(deposit m.Deposit, outcome *m.CancellationOutcome) int {
+	refund := deposit.AmountCents
+	if outcome != nil {
+		refund -= min(outcome.PenaltyCents, deposit.AmountCents)
+	}
+	return refund
+}
+
+// refundDeposit devuelve el depósito de una reserva cancelada. Se ejecuta
+// como job para reintentarlo si el proveedor falla; la reserva puede estar
+// borrada.
+func refundDeposit(ctx context.Context, job m.Job) error {
+	objectID, err := primitive.ObjectIDFromHex(job.Data["reservation_id"])
+	if err != nil {
+		return nil
+	}
+	collection := mongoClient.Database("reservations-db").Collection("reservations")
+	filter := bson.M{"_id": objectID, "restaurantid": job.Data["restaurant_id"]}
+	var reservation m.Reservation
+	err = collection.FindOne(ctx, filter).Decode(&reservation)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if reservation.Deposit == nil || reservation.Deposit.Status != DepositPaid {
+		return nil
+	}
+
+	now := time.Now()
+	update := bson.M{"deposit.status": DepositRetained, "updateat": now}
+	if amount := depositRefund(*reservation.Deposit, reservation.Cancellation); amount > 0 {
+		if paymentProvider == nil {
+			return fmt.Errorf("no payment provider configured to refund the deposit")
+		}
+		if _, err = paymentProvider.Refund(ctx, reservation.Deposit.PaymentId, amount); err != nil {
+			return err
+		}
+		update = bson.M{"deposit.status": DepositRefunded, "deposit.refundedcents": amount, "deposit.refundedat": now, "updateat": now}
+	}
+	_, err = updateReservationVersioned(ctx, filter, reservation, update, func([]m.FieldChange) []string {
+		return []string{events.ReservationUpdated}
+	})
+	return err
+}
+
+func toPbDeposit(deposit *m.Deposit) *pb.Deposit {
+	if deposit == nil {
+		return nil
+	}
+	pbDeposit := &pb.Deposit{
+		AmountCents:   int32(deposit.AmountCents),
+		Currency:      deposit.Currency,
+		PaymentId:     deposit.PaymentId,
+		CheckoutUrl:   deposit.CheckoutUrl,
+		Status:        deposit.Status,
+		ExpiresAt:     deposit.ExpiresAt.Format(time.RFC3339),
+		RefundedCents: int32(deposit.RefundedCents),
+	}
+	if !deposit.PaidAt.IsZero() {
+		pbDeposit.PaidAt = deposit.PaidAt.Format(time.RFC3339)
+	}
+	if !deposit.RefundedAt.IsZero() {
+		pbDeposit.RefundedAt = deposit.RefundedAt.Format(time.RFC3339)
+	}
+	return pbDeposit
+}
+
+func toPbDepositPolicy(policy m.DepositPolicy) *pb.DepositPolicy {
+	var events []*pb.SpecialEvent
+	for _, event := range policy.Events {
+		events = append(events, &pb.SpecialEvent{Name: event.Name, Date: event.Date})
+	}
+	return &pb.DepositPolicy{
+		AmountPerGuestCents:  int32(policy.AmountPerGuestCents),
+		Currency:             policy.Currency,
+		MinGuestCount:        int32(policy.MinGuestCount),
+		Events:               events,
+		PaymentWindowMinutes: int32(policy.PaymentWindowMinutes),
+	}
+}
+
+func fromPbDepositPolicy(policy *pb.DepositPolicy) m.DepositPolicy {
+	if policy == nil {
+		return m.DepositPolicy{}
+	}
+	var events []m.SpecialEvent
+	for _, event := range policy.Events {
+		events = append(events, m.SpecialEvent{Name: event.Name, Date: event.Date})
+	}
+	return m.DepositPolicy{
+		AmountPerGuestCents:  int(policy.AmountPerGuestCents),
+		Currency:             policy.Currency,
+		MinGuestCount:        int(policy.MinGuestCount),
+		Events:               events,
+		PaymentWindowMinutes: int(policy.PaymentWindowMinutes),
+	}
+}
+
+func validateDepositPolicy(policy m.DepositPolicy) error {
+	if policy.AmountPerGuestCents < 0 || policy.MinGuestCount < 0 || policy.PaymentWindowMinutes < 0 {
+		return fmt.Errorf("deposit policy cannot be negative")
+	}
+	if policy.AmountPerGuestCents > 0 && len(policy.Currency) != 3 {
+		return fmt.Errorf("deposit currency must be a 3-letter ISO 4217 code")
+	}
+	for _, event := range policy.Events {
+		if event.Name == "" {
+			return fmt.Errorf("special event name is required")
+		}
+		if _, err := time.Parse("02-01-2006", event.Date); err != nil {
+			return fmt.Errorf("invalid special event date format, expected dd-mm-yyyy")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,251 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/mail"
+	"time"
+
+	m "ms-reservas/models"
+	"ms-reservas/notifications"
+	pb "ms-reservas/protos_pb/proto"
+	"ms-reservas/tracing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// EnsureGuestIndexes crea el índice único por cliente.
+func EnsureGuestIndexes(ctx context.Context) error {
+	collection := mongoClient.Database("reservations-db").Collection("guest_profiles")
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "userid", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to create guest indexes", "error", err)
+	}
+	return err
+}
+
+// GET PROFILE
+func GetGuestProfileHandler(ctx context.Context, req *pb.GetGuestProfileRequest) (*pb.GuestProfile, error) {
+	ctx, span := tracing.Start(ctx, "controllers.GetGuestProfileHandler")
+	defer span.End()
+
+	profile, err := GetGuestProfile(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+	return toPbGuestProfile(profile), nil
+}
+
+// GetGuestProfile devuelve el perfil del cliente, vacío si aún no tiene.
+func GetGuestProfile(ctx context.Context, userID string) (m.GuestProfile, error) {
+	ctx, span := tracing.Start(ctx, "controllers.GetGuestProfile")
+	defer span.End()
+
+	if userID == "" {
+		return m.GuestProfile{}, fmt.Errorf("userID is required")
+	}
+	collection := mongoClient.Database("reservations-db").Collection("guest_profiles")
+	profile := m.GuestProfile{UserId: userID}
+	err := collection.FindOne(ctx, bson.M{"userid": userID}).Decode(&profile)
+	if err != nil && err != mongo.ErrNoDocuments {
+		slog.ErrorContext(ctx, "failed to find guest profile", "error", err)
+		return m.GuestProfile{}, err
+	}
+	return profile, nil
+}
+
+// UPDATE PROFILE
+func UpdateGuestProfileHandler(ctx context.Context, req *pb.UpdateGuestProfileRequest) (*pb.GuestProfile, error) {
+	ctx, span := tracing.Start(ctx, "controllers.UpdateGuestProfileHandler")
+	defer span.End()
+
+	update := bson.M{}
+	if req.Name != "" {
+		update["name"] = req.Name
+	}
+	if req.Email != "" {
+		if _, err := mail.ParseAddress(req.Email); err != nil {
+			return nil, fmt.Errorf("invalid email")
+		}
+		update["email"] = req.Email
+	}
+	if req.Phone != "" {
+		update["phone"] = req.Phone
+	}
+	if req.Language != "" {
+		if !notifications.SupportedLanguage(req.Language) {
+			return nil, fmt.Errorf("invalid language, expected one of %v", notifications.Languages)
+		}
+		update["language"] = req.Language
+	}
+	if req.Allergies != nil {
+		update["allergies"] = req.Allergies
+	}
+	if req.DietaryNotes != "" {
+		update["dietarynotes"] = req.DietaryNotes
+	}
+	if req.Preferences != "" {
+		update["preferences"] = req.Preferences
+	}
+	if req.Tags != nil {
+		update["tags"] = req.Tags
+	}
+
+	profile, err := UpdateGuestProfile(ctx, req.UserId, update)
+	if err != nil {
+		return nil, err
+	}
+	return toPbGuestProfile(profile), nil
+}
+
+// UpdateGuestProfile aplica update al perfil del cliente, creándolo si no
+// existe, y devuelve el perfil resultante.
+func UpdateGuestProfile(ctx context.Context, userID string, update bson.M) (m.GuestProfile, error) {
+	ctx, span := tracing.Start(ctx, "controllers.UpdateGuestProfile")
+	defer span.End()
+
+	if userID == "" {
+		return m.GuestProfile{}, fmt.Errorf("userID is required")
+	}
+	now := time.Now()
+	update["updateat"] = now
+
+	collection := mongoClient.Database("reservations-db").Collection("guest_profiles")
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	var profile m.GuestProfile
+	err := collection.FindOneAndUpdate(ctx, bson.M{"userid": userID}, bson.M{
+		"$set":         update,
+		"$setOnInsert": bson.M{"createat": now, "visitcount": 0},
+	}, opts).Decode(&profile)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to update guest profile", "error", err)
+		return m.GuestProfile{}, err
+	}
+	return profile, nil
+}
+
+// linkGuestProfile crea el perfil del cliente con los datos de contacto de
+// su primera reserva. No sobrescribe un perfil existente: el cliente lo
+// mantiene con UpdateGuestProfile.
+func linkGuestProfile(ctx context.Context, reservation m.Reservation) {
+	if reservation.UserId == "" || anonymousWalkIn(reservation) {
+		return
+	}
+	now := time.Now()
+	collection := mongoClient.Database("reservations-db").Collection("guest_profiles")
+	_, err := collection.UpdateOne(ctx, bson.M{"userid": reservation.UserId}, bson.M{
+		"$setOnInsert": bson.M{
+			"name":       reservation.GuestName,
+			"email":      reservation.GuestEmail,
+			"phone":      reservation.GuestPhone,
+			"language":   reservation.Language,
+			"visitcount": 0,
+			"createat":   now,
+		},
+	}, options.Update().SetUpsert(true))
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to link guest profile", "user_id", reservation.UserId, "error", err)
+	}
+}
+
+// recordGuestVisit suma una visita al perfil del cliente cuando su reserva
+// se completa.
+func recordGuestVisit(ctx context.Context, reservation m.Reservation) {
+	if reservation.UserId == "" || anonymousWalkIn(reservation) {
+		return
+	}
+	visit := reservation.SeatedAt
+	if visit.IsZero() {
+		visit = time.Now()
+	}
+	collection := mongoClient.Database("reservations-db").Collection("guest_profiles")
+	_, err := collection.UpdateOne(ctx, bson.M{"userid": reservation.UserId}, bson.M{
+		"$inc": bson.M{"visitcount": 1},
+		"$max": bson.M{"lastvisit": visit},
+		"$set": bson.M{"updateat": time.Now()},
+		"$setOnInsert": bson.M{
+			"name":     reservation.GuestName,
+			"createat": time.Now(),
+		},
+	}, options.Update().SetUpsert(true))
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to record guest visit", "user_id", reservation.UserId, "error", err)
+	}
+}
+
+// guestSummaries devuelve el resumen del perfil de los clientes de las
+// reservas, por UserId. Un fallo solo deja las reservas sin resumen.
+func guestSummaries(ctx context.Context, reservations []m.Reservation) map[string]*pb.GuestSummary {
+	ctx, span := tracing.Start(ctx, "controllers.guestSummaries")
+	defer span.End()
+
+	summaries := map[string]*pb.GuestSummary{}
+	var userIDs bson.A
+	seen := map[string]bool{}
+	for _, reservation := range reservations {
+		if reservation.UserId != "" && !seen[reservation.UserId] {
+			seen[reservation.UserId] = true
+			userIDs = append(userIDs, reservation.UserId)
+		}
+	}
+	if len(userIDs) == 0 {
+		return summaries
+	}
+
+	collection := mongoClient.Database("reservations-db").Collection("guest_profiles")
+	cursor, err := collection.Find(ctx, bson.M{"userid": bson.M{"$in": userIDs}})
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to find guest profiles", "error", err)
+		return summaries
+	}
+	var profiles []m.GuestProfile
+	if err = cursor.All(ctx, &profiles); err != nil {
+		slog.ErrorContext(ctx, "failed to decode guest profiles", "error", err)
+		return summaries
+	}
+	for _, profile := range profiles {
+		summary := &pb.GuestSummary{
+			Name:         profile.Name,
+			Tags:         profile.Tags,
+			Allergies:    profile.Allergies,
+			DietaryNotes: profile.DietaryNotes,
+			VisitCount:   int32(profile.VisitCount),
+		}
+		if !profile.LastVisit.IsZero() {
+			summary.LastVisit = profile.LastVisit.Format(time.RFC3339)
+		}
+		summaries[profile.UserId] = summary
+	}
+	return summaries
+}
+
+func toPbGuestProfile(profile m.GuestProfile) *pb.GuestProfile {
+	pbProfile := &pb.GuestProfile{
+		UserId:       profile.UserId,
+		Name:         profile.Name,
+		Email:        profile.Email,
+		Phone:        profile.Phone,
+		Language:     profile.Language,
+		Allergies:    profile.Allergies,
+		DietaryNotes: profile.DietaryNotes,
+		Preferences:  profile.Preferences,
+		Tags:         profile.Tags,
+		VisitCount:   int32(profile.VisitCount),
+	}
+	if !profile.LastVisit.IsZero() {
+		pbProfile.LastVisit = profile.LastVisit.Format(time.RFC3339)
+	}
+	if !profile.CreateAt.IsZero() {
+		pbProfile.CreatedAt = profile.CreateAt.Format(time.RFC3339)
+	}
+	if !profile.UpdateAt.IsZero() {
+		pbProfile.UpdatedAt = profile.UpdateAt.Format(time.RFC3339)
+	}
+	return pbProfile
+}
@@ -0,0 +1,150 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	m "ms-reservas/models"
+	pb "ms-reservas/protos_pb/proto"
+	"ms-reservas/tracing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrVersionConflict indica que la reserva cambió desde que el cliente la
+// leyó, o durante la propia actualización.
+var ErrVersionConflict = errors.New("reservation was modified concurrently, reload it and try again")
+
+// historyFields son los campos cuyo cambio queda en el historial de la
+// reserva, en el orden en que se listan.
+// Las notas internas del personal no se registran porque el historial es
+// visible para el cliente.
+var historyFields = []string{"tableid", "reservationdate", "reservationtime", "guestcount", "status", "occasion", "dietaryrestrictions", "notes", "deleted"}
+
+// versionFilter compara la versión guardada con version. Las reservas
+// anteriores al versionado no tienen el campo y cuentan como versión 0.
+func versionFilter(version int) interface{} {
+	if version == 0 {
+		return bson.M{"$in": bson.A{0, nil}}
+	}
+	return version
+}
+
+// reservationChanges devuelve los campos de update que modifican la reserva.
+func reservationChanges(before m.Reservation, update bson.M) []m.FieldChange {
+	var changes []m.FieldChange
+	for _, field := range historyFields {
+		value, ok := update[field]
+		if !ok {
+			continue
+		}
+		oldValue, newValue := reservationField(before, field), fmt.Sprint(value)
+		if oldValue != newValue {
+			changes = append(changes, m.FieldChange{Field: field, OldValue: oldValue, NewValue: newValue})
+		}
+	}
+	return changes
+}
+
+func reservationField(reservation m.Reservation, field string) string {
+	switch field {
+	case "tableid":
+		return reservation.TableId
+	case "reservationdate":
+		return reservation.ReservationDate
+	case "reservationtime":
+		return reservation.ReservationTime
+	case "guestcount":
+		return fmt.Sprint(reservation.GuestCount)
+	case "status":
+		return reservation.Status
+	case "occasion":
+		return reservation.Occasion
+	case "dietaryrestrictions":
+		return fmt.Sprint(reservation.DietaryRestrictions)
+	case "notes":
+		return reservation.Notes
+	case "deleted":
+		return fmt.Sprint(reservation.Deleted)
+	}
+	return ""
+}
+
+// versionedUpdate completa update para que incremente la versión y añada
+// los cambios al historial. Devuelve también los campos modificados.
+func versionedUpdate(ctx context.Context, before m.Reservation, update bson.M) (bson.M, []m.FieldChange) {
+	change := m.ReservationChange{
+		Version:   before.Version + 1,
+		Actor:     actorFromContext(ctx),
+		ChangedAt: time.Now(),
+		Changes:   reservationChanges(before, update),
+	}
+	return bson.M{
+		"$set":  update,
+		"$inc":  bson.M{"version": 1},
+		"$push": bson.M{"history": change},
+	}, change.Changes
+}
+
+// updateReservationVersioned aplica update a la reserva before solo si
+// sigue en la misma versión, y guarda en el outbox los eventos que elija
+// eventTypes a partir de los campos modificados. Ambas escrituras van en la
+// misma transacción. Devuelve la reserva resultante.
+func updateReservationVersioned(ctx context.Context, filter bson.M, before m.Reservation, update bson.M, eventTypes func([]m.FieldChange) []string) (m.Reservation, error) {
+	collection := mongoClient.Database("reservations-db").Collection("reservations")
+	versioned, changes := versionedUpdate(ctx, before, update)
+
+	filter["version"] = versionFilter(before.Version)
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var after m.Reservation
+	err := withTransaction(ctx, func(ctx context.Context) error {
+		err := collection.FindOneAndUpdate(ctx, filter, versioned, opts).Decode(&after)
+		if err == mongo.ErrNoDocuments {
+			return ErrVersionConflict
+		}
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to update reservation", "error", err)
+			return err
+		}
+		return enqueueEvents(ctx, eventTypes(changes), after, changes)
+	})
+	return after, err
+}
+
+// GET HISTORY
+func GetReservationHistoryHandler(ctx context.Context, req *pb.GetReservationByIDRequest) (*pb.ReservationHistory, error) {
+	ctx, span := tracing.Start(ctx, "controllers.GetReservationHistoryHandler")
+	defer span.End()
+
+	reservation, err := GetReservationByID(ctx, req.RestaurantId, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []*pb.ReservationChange
+	for _, change := range reservation.History {
+		pbChange := &pb.ReservationChange{
+			Version:   int32(change.Version),
+			Actor:     change.Actor,
+			ChangedAt: change.ChangedAt.Format(time.RFC3339),
+		}
+		for _, field := range change.Changes {
+			pbChange.Changes = append(pbChange.Changes, &pb.FieldChange{
+				Field:    field.Field,
+				OldValue: field.OldValue,
+				NewValue: field.NewValue,
+			})
+		}
+		changes = append(changes, pbChange)
+	}
+	return &pb.ReservationHistory{
+		ReservationId: reservation.ID,
+		Version:       int32(reservation.Version),
+		Changes:       changes,
+	}, nil
+}
@@ -0,0 +1,271 @@
+package controllers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"time"
+
+	m "ms-reservas/models"
+	pb "ms-reservas/protos_pb/proto"
+	"ms-reservas/tracing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// holdTTL lee HOLD_TTL_MINUTES, cuánto dura un bloqueo temporal de mesa;
+// por defecto 10 minutos.
+func holdTTL() time.Duration {
+	return envMinutes("HOLD_TTL_MINUTES", 10*time.Minute)
+}
+
+// EnsureHoldIndexes crea los índices de la colección de bloqueos: un índice
+// TTL para que Mongo elimine los bloqueos caducados, el token único y una
+// única reserva temporal por mesa y franja.
+func EnsureHoldIndexes(ctx context.Context) error {
+	ctx, span := tracing.Start(ctx, "controllers.EnsureHoldIndexes")
+	defer span.End()
+
+	collection := mongoClient.Database("reservations-db").Collection("holds")
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "expiresat", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+		{
+			Keys:    bson.D{{Key: "token", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "tableid", Value: 1}, {Key: "reservationdate", Value: 1}, {Key: "reservationtime", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to create hold indexes", "error", err)
+		return err
+	}
+	return nil
+}
+
+// HOLD
+func HoldSlotHandler(ctx context.Context, req *pb.HoldSlotRequest) (*pb.HoldSlotResponse, error) {
+	ctx, span := tracing.Start(ctx, "controllers.HoldSlotHandler")
+	defer span.End()
+
+	tableID := req.TableId
+	if tableID == "" {
+		table, err := AssignTable(ctx, req.RestaurantId, req.ReservationDate, []string{req.ReservationTime}, int(req.GuestCount))
+		if err != nil {
+			return &pb.HoldSlotResponse{Message: "Failed to assign table", Success: false}, err
+		}
+		if table == nil {
+			return &pb.HoldSlotResponse{Message: "No table available for this date and party size", Success: false}, nil
+		}
+		tableID = table.ID
+	}
+
+	exists, err := ReservationExists(ctx, tableID, req.ReservationDate, req.ReservationTime)
+	if err != nil {
+		return &pb.HoldSlotResponse{Message: "Failed to check existing reservations", Success: false}, err
+	}
+	if exists {
+		return &pb.HoldSlotResponse{Message: "Slot is already reserved or held", Success: false}, nil
+	}
+
+	hold := m.Hold{
+		RestaurantId:    req.RestaurantId,
+		UserId:          req.UserId,
+		TableId:         tableID,
+		ReservationDate: req.ReservationDate,
+		ReservationTime: req.ReservationTime,
+		GuestCount:      int(req.GuestCount),
+		CreateAt:        time.Now(),
+	}
+	hold, err = HoldSlot(ctx, hold)
+	if err != nil {
+		return &pb.HoldSlotResponse{Message: "Failed to hold slot", Success: false}, err
+	}
+
+	return &pb.HoldSlotResponse{
+		Message:   "Slot held successfully",
+		Success:   true,
+		HoldToken: hold.Token,
+		TableId:   hold.TableId,
+		ExpiresAt: hold.ExpiresAt.Format(time.RFC3339),
+	}, nil
+}
+
+func HoldSlot(ctx context.Context, hold m.Hold) (m.Hold, error) {
+	ctx, span := tracing.Start(ctx, "controllers.HoldSlot")
+	defer span.End()
+
+	if hold.UserId == "" {
+		return hold, fmt.Errorf("userID is required")
+	}
+	if hold.GuestCount <= 0 {
+		return hold, fmt.Errorf("guestCount must be greater than 0")
+	}
+
+	const dateFormat = "02-01-2006"
+	if _, err := time.Parse(dateFormat, hold.ReservationDate); err != nil {
+		return hold, fmt.Errorf("invalid date format, expected dd-mm-yyyy")
+	}
+
+	const timeFormat = "15:04"
+	reservationTime, err := time.Parse(timeFormat, hold.ReservationTime)
+	if err != nil {
+		return hold, fmt.Errorf("invalid time format, expected HH:MM")
+	}
+	if reservationTime.Minute() != 0 {
+		return hold, fmt.Errorf("reservation time must be end in 00")
+	}
+
+	restaurant, err := GetRestaurantByID(ctx, hold.RestaurantId)
+	if err != nil {
+		return hold, fmt.Errorf("restaurant not found")
+	}
+	if _, err = GetRestaurantTable(ctx, hold.RestaurantId, hold.TableId); err != nil {
+		return hold, err
+	}
+	err = ValidateRestaurantSlot(restaurant, hold.ReservationDate, hold.ReservationTime, hold.GuestCount)
+	if err != nil {
+		return hold, err
+	}
+
+	token, err := newHoldToken()
+	if err != nil {
+		return hold, err
+	}
+	hold.Token = token
+	hold.ExpiresAt = hold.CreateAt.Add(holdTTL())
+
+	collection := mongoClient.Database("reservations-db").Collection("holds")
+
+	// El índice TTL puede tardar hasta un minuto en borrar un bloqueo
+	// caducado; se elimina aquí para que no choque con el índice único.
+	_, err = collection.DeleteMany(ctx, bson.M{
+		"tableid":         hold.TableId,
+		"reservationdate": hold.ReservationDate,
+		"reservationtime": hold.ReservationTime,
+		"expiresat":       bson.M{"$lte": time.Now()},
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to delete expired holds", "error", err)
+		return hold, err
+	}
+
+	_, err = collection.InsertOne(ctx, hold)
+	if mongo.IsDuplicateKeyError(err) {
+		return hold, fmt.Errorf("slot is already held")
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to insert hold", "error", err)
+		return hold, err
+	}
+	return hold, nil
+}
+
+// GetActiveHold busca un bloqueo vigente por su token.
+func GetActiveHold(ctx context.Context, token string) (*m.Hold, error) {
+	ctx, span := tracing.Start(ctx, "controllers.GetActiveHold")
+	defer span.End()
+
+	collection := mongoClient.Database("reservations-db").Collection("holds")
+	var hold m.Hold
+	err := collection.FindOne(ctx, bson.M{
+		"token":     token,
+		"expiresat": bson.M{"$gt": time.Now()},
+	}).Decode(&hold)
+	if err == mongo.ErrNoDocuments {
+		return nil, fmt.Errorf("hold not found or expired")
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to find hold", "error", err)
+		return nil, err
+	}
+	return &hold, nil
+}
+
+func ReleaseHold(ctx context.Context, token string) error {
+	ctx, span := tracing.Start(ctx, "controllers.ReleaseHold")
+	defer span.End()
+
+	collection := mongoClient.Database("reservations-db").Collection("holds")
+	_, err := collection.DeleteOne(ctx, bson.M{"token": token})
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to delete hold", "error", err)
+		return err
+	}
+	return nil
+}
+
+// SlotHeld indica si la mesa tiene un bloqueo vigente en esa franja,
+// ignorando el bloqueo con el token exceptToken.
+func SlotHeld(ctx context.Context, tableId, reservationDate, reservationTime, exceptToken string) (bool, error) {
+	ctx, span := tracing.Start(ctx, "controllers.SlotHeld")
+	defer span.End()
+
+	filter := bson.M{
+		"tableid":         tableId,
+		"reservationdate": reservationDate,
+		"reservationtime": reservationTime,
+		"expiresat":       bson.M{"$gt": time.Now()},
+	}
+	if exceptToken != "" {
+		filter["token"] = bson.M{"$ne": exceptToken}
+	}
+
+	collection := mongoClient.Database("reservations-db").Collection("holds")
+	count, err := collection.CountDocuments(ctx, filter)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to check holds", "error", err)
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// getHeldTables devuelve las mesas con bloqueos vigentes en la fecha y, si
+// se indican, solo en esas franjas.
+func getHeldTables(ctx context.Context, reservationDate string, slots []string) (map[string]bool, error) {
+	ctx, span := tracing.Start(ctx, "controllers.getHeldTables")
+	defer span.End()
+
+	filter := bson.M{
+		"reservationdate": reservationDate,
+		"expiresat":       bson.M{"$gt": time.Now()},
+	}
+	if slots != nil {
+		filter["reservationtime"] = bson.M{"$in": slots}
+	}
+
+	collection := mongoClient.Database("reservations-db").Collection("holds")
+	cursor, err := collection.Find(ctx, filter)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to find holds", "error", err)
+		return nil, err
+	}
+	var holds []m.Hold
+	if err = cursor.All(ctx, &holds); err != nil {
+		slog.ErrorContext(ctx, "failed to decode holds", "error", err)
+		return nil, err
+	}
+
+	held := make(map[string]bool)
+	for _, hold := range holds {
+		held[hold.TableId] = true
+	}
+	return held, nil
+}
+
+func newHoldToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,188 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"ms-reservas/events"
+	"ms-reservas/jobs"
+	m "ms-reservas/models"
+	"ms-reservas/tracing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Tipos de job de las reservas
+const (
+	JobReservationReminder     = "reservation_reminder"
+	JobReservationNoShow       = "reservation_no_show"
+	JobReservationAutoComplete = "reservation_auto_complete"
+)
+
+// jobRunner ejecuta los jobs programados; se crea junto con el cliente de
+// Mongo en SetMongoClient.
+var jobRunner *jobs.Runner
+
+func newJobRunner(client *mongo.Client) *jobs.Runner {
+	runner := jobs.NewRunner(client.Database("reservations-db").Collection("jobs"))
+	runner.Handle(JobReservationReminder, sendReservationReminder)
+	runner.Handle(JobReservationNoShow, markReservationNoShow)
+	runner.Handle(JobReservationAutoComplete, completeSeatedReservation)
+	runner.Handle(JobReservationPaymentExpiry, expireUnpaidReservation)
+	runner.Handle(JobDepositRefund, refundDeposit)
+	runner.Handle(JobReservationNotification, sendReservationNotification)
+	return runner
+}
+
+// reminderLead lee de REMINDER_HOURS con cuánta antelación se envía el
+// recordatorio de una reserva.
+func reminderLead() time.Duration {
+	return envHours("REMINDER_HOURS", 24*time.Hour)
+}
+
+// noShowGrace lee de NO_SHOW_GRACE_MINUTES cuánto se espera al cliente
+// antes de marcar la reserva como no presentada.
+func noShowGrace() time.Duration {
+	return envMinutes("NO_SHOW_GRACE_MINUTES", 15*time.Minute)
+}
+
+// EnsureJobIndexes crea los índices de la colección de jobs.
+func EnsureJobIndexes(ctx context.Context) error {
+	return jobRunner.EnsureIndexes(ctx)
+}
+
+// StartJobRunner ejecuta periódicamente los jobs vencidos hasta que se
+// cancele ctx.
+func StartJobRunner(ctx context.Context, interval time.Duration) {
+	jobRunner.Start(ctx, interval)
+}
+
+func reservationJobKey(jobType, reservationID string) string {
+	return jobType + ":" + reservationID
+}
+
+// reservationSlot devuelve el inicio de la reserva en la zona horaria de su
+// restaurante.
+func reservationSlot(ctx context.Context, reservation m.Reservation) (time.Time, *m.Restaurant, error) {
+	restaurant, err := GetRestaurantByID(ctx, reservation.RestaurantId)
+	if err != nil {
+		return time.Time{}, nil, err
+	}
+	slot, err := time.ParseInLocation("02-01-2006 15:04", reservation.ReservationDate+" "+reservation.ReservationTime, restaurantLocation(restaurant))
+	if err != nil {
+		return time.Time{}, nil, err
+	}
+	return slot, restaurant, nil
+}
+
+// scheduleReservationJobs programa, o reprograma si cambió la franja, el
+// recordatorio y la marca de no presentada de la reserva, y su cierre
+// automático cuando se sienta. Los handlers vuelven a comprobar el estado
+// al ejecutarse, así que cancelar o completar la reserva no obliga a borrar
+// sus jobs. Un fallo se registra pero no deshace la reserva.
+func scheduleReservationJobs(ctx context.Context, reservation m.Reservation) {
+	ctx, span := tracing.Start(ctx, "controllers.scheduleReservationJobs")
+	defer span.End()
+
+	data := map[string]string{"restaurant_id": reservation.RestaurantId, "reservation_id": reservation.ID}
+	// Hasta que se pague el depósito solo corre el plazo de pago
+	if reservation.Status == StatusPendingPayment && reservation.Deposit != nil {
+		jobRunner.Schedule(ctx, JobReservationPaymentExpiry, reservationJobKey(JobReservationPaymentExpiry, reservation.ID), reservation.Deposit.ExpiresAt, data)
+		return
+	}
+	if !awaitingGuest(reservation.Status) && reservation.Status != "sentada" {
+		return
+	}
+	slot, restaurant, err := reservationSlot(ctx, reservation)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to schedule reservation jobs", "reservation_id", reservation.ID, "error", err)
+		return
+	}
+
+	if awaitingGuest(reservation.Status) {
+		// Si la reserva se hace con menos antelación que el recordatorio,
+		// basta con la confirmación.
+		if remindAt := slot.Add(-reminderLead()); remindAt.After(time.Now()) {
+			jobRunner.Schedule(ctx, JobReservationReminder, reservationJobKey(JobReservationReminder, reservation.ID), remindAt, data)
+		}
+		jobRunner.Schedule(ctx, JobReservationNoShow, reservationJobKey(JobReservationNoShow, reservation.ID), slot.Add(noShowGrace()), data)
+		return
+	}
+	jobRunner.Schedule(ctx, JobReservationAutoComplete, reservationJobKey(JobReservationAutoComplete, reservation.ID), seatedUntil(reservation, slot, restaurant), data)
+}
+
+// seatedUntil devuelve cuándo termina el tiempo de mesa de una reserva
+// sentada, contado desde que se sentó si fue después de la hora.
+func seatedUntil(reservation m.Reservation, slot time.Time, restaurant *m.Restaurant) time.Time {
+	start := slot
+	if reservation.SeatedAt.After(start) {
+		start = reservation.SeatedAt
+	}
+	return start.Add(restaurantTurnTime(restaurant))
+}
+
+// jobReservation carga la reserva de un job. Devuelve nil si ya no existe o
+// está borrada: el job no tiene nada que hacer.
+func jobReservation(ctx context.Context, job m.Job) (*m.Reservation, error) {
+	reservation, err := GetReservationByID(ctx, job.Data["restaurant_id"], job.Data["reservation_id"])
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	return reservation, err
+}
+
+// sendReservationReminder deja en el outbox el evento de recordatorio; las
+// notificaciones lo envían al cliente.
+func sendReservationReminder(ctx context.Context, job m.Job) error {
+	reservation, err := jobReservation(ctx, job)
+	if err != nil || reservation == nil || !awaitingGuest(reservation.Status) {
+		return err
+	}
+	slot, _, err := reservationSlot(ctx, *reservation)
+	if err != nil {
+		return err
+	}
+	if !time.Now().Before(slot) {
+		return nil
+	}
+	return enqueueEvents(ctx, []string{events.ReservationReminder}, *reservation, nil)
+}
+
+// markReservationNoShow marca como no presentada la reserva que sigue
+// confirmada pasado el margen de cortesía.
+func markReservationNoShow(ctx context.Context, job m.Job) error {
+	reservation, err := jobReservation(ctx, job)
+	if err != nil || reservation == nil || !awaitingGuest(reservation.Status) {
+		return err
+	}
+	slot, _, err := reservationSlot(ctx, *reservation)
+	if err != nil {
+		return err
+	}
+	if due := slot.Add(noShowGrace()); time.Now().Before(due) {
+		return jobs.RunAgainAt(due)
+	}
+	update := bson.M{"status": StatusNoShow, "updateat": time.Now()}
+	return UpdateReservation(ctx, reservation.RestaurantId, reservation.ID, update, reservation.Version)
+}
+
+// completeSeatedReservation completa la reserva sentada cuando ha pasado su
+// tiempo de mesa, contado desde que se sentó si fue después de la hora.
+func completeSeatedReservation(ctx context.Context, job m.Job) error {
+	reservation, err := jobReservation(ctx, job)
+	if err != nil || reservation == nil || reservation.Status != "sentada" {
+		return err
+	}
+	slot, restaurant, err := reservationSlot(ctx, *reservation)
+	if err != nil {
+		return err
+	}
+	if due := seatedUntil(*reservation, slot, restaurant); time.Now().Before(due) {
+		return jobs.RunAgainAt(due)
+	}
+	update := bson.M{"status": "completada", "updateat": time.Now()}
+	return UpdateReservation(ctx, reservation.RestaurantId, reservation.ID, update, reservation.Version)
+}
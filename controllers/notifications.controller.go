@@ -0,0 +1,109 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"ms-reservas/events"
+	m "ms-reservas/models"
+	"ms-reservas/notifications"
+	"ms-reservas/tracing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// JobReservationNotification envía al cliente la notificación de un evento.
+const JobReservationNotification = "reservation_notification"
+
+// notifier envía las notificaciones a los clientes. Sin notifier los
+// eventos no generan notificaciones.
+var notifier *notifications.Notifier
+
+func SetNotifier(n *notifications.Notifier) {
+	notifier = n
+}
+
+// NotificationPublisher programa un job por cada evento del outbox que deba
+// notificarse al cliente. El envío lo hace el job, con sus propios
+// reintentos: un email o un teléfono que fallan no bloquean el relay y,
+// agotados los intentos, el job queda como fallido.
+type NotificationPublisher struct{}
+
+func (NotificationPublisher) Publish(ctx context.Context, event events.Event) error {
+	ctx, span := tracing.Start(ctx, "controllers.NotificationPublisher.Publish")
+	defer span.End()
+
+	if notifier == nil {
+		return nil
+	}
+	kind, payload, err := notifications.EventKind(event)
+	if err != nil || kind == "" {
+		return err
+	}
+	// El evento no lleva los datos de contacto ni el código: el job guarda
+	// la referencia y los lee de la reserva al enviar
+	data := map[string]string{
+		"event_id":       event.ID,
+		"kind":           kind,
+		"restaurant_id":  payload.Reservation.RestaurantId,
+		"reservation_id": payload.Reservation.ID,
+	}
+	// La clave es la del evento: si el relay lo reintenta, el job ya
+	// programado no se duplica.
+	return jobRunner.Enqueue(ctx, JobReservationNotification, JobReservationNotification+":"+event.ID, time.Now(), data)
+}
+
+// sendReservationNotification envía la notificación del job con los datos
+// actuales de la reserva.
+func sendReservationNotification(ctx context.Context, job m.Job) error {
+	if notifier == nil {
+		return nil
+	}
+	if job.Data["reservation_id"] == "" {
+		// Los jobs programados antes de guardar la referencia llevan la
+		// reserva completa en el evento
+		kind, payload, err := notifications.EventKind(events.Event{
+			ID:      job.Data["event_id"],
+			Type:    job.Data["event_type"],
+			Payload: json.RawMessage(job.Data["payload"]),
+		})
+		if err != nil || kind == "" {
+			return err
+		}
+		return notifier.Notify(ctx, kind, payload.Reservation)
+	}
+
+	reservation, err := notificationReservation(ctx, job.Data["restaurant_id"], job.Data["reservation_id"])
+	if err == mongo.ErrNoDocuments {
+		// La reserva se purgó antes del envío: ya no hay a quién notificar
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return notifier.Notify(ctx, job.Data["kind"], reservation)
+}
+
+// notificationReservation lee la reserva que se notifica. Incluye las
+// borradas: borrar una reserva pendiente es cancelarla y el cliente debe
+// recibir la cancelación.
+func notificationReservation(ctx context.Context, restaurantID, id string) (m.Reservation, error) {
+	ctx, span := tracing.Start(ctx, "controllers.notificationReservation")
+	defer span.End()
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return m.Reservation{}, err
+	}
+	collection := mongoClient.Database("reservations-db").Collection("reservations")
+	var reservation m.Reservation
+	err = collection.FindOne(ctx, bson.M{"_id": objectID, "restaurantid": restaurantID}).Decode(&reservation)
+	if err != nil && err != mongo.ErrNoDocuments {
+		slog.ErrorContext(ctx, "failed to find reservation for notification", "error", err)
+	}
+	return reservation, err
+}
@@ -0,0 +1,211 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"ms-reservas/events"
+	m "ms-reservas/models"
+	"ms-reservas/tracing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// outboxBatchSize limita los eventos publicados en cada pasada del relay.
+const outboxBatchSize = 100
+
+// EnsureOutboxIndexes crea el índice con el que el relay busca los eventos
+// pendientes en orden.
+func EnsureOutboxIndexes(ctx context.Context) error {
+	collection := mongoClient.Database("reservations-db").Collection("outbox")
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "published", Value: 1}, {Key: "occurredat", Value: 1}},
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to create outbox indexes", "error", err)
+	}
+	return err
+}
+
+// transactionKey marca el contexto de una transacción en curso.
+type transactionKey struct{}
+
+// withTransaction ejecuta fn en una transacción de Mongo (requiere un
+// replica set). fn debe usar el contexto que recibe en todas sus
+// operaciones para que formen parte de la transacción. Si ctx ya está en
+// una transacción, fn se une a ella.
+func withTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if ctx.Value(transactionKey{}) != nil {
+		return fn(ctx)
+	}
+	session, err := mongoClient.StartSession()
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to start mongo session", "error", err)
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(context.WithValue(ctx, transactionKey{}, true), func(sessionCtx mongo.SessionContext) (interface{}, error) {
+		return nil, fn(sessionCtx)
+	})
+	return err
+}
+
+// reservationEvents decide qué eventos genera un cambio en una reserva.
+func reservationEvents(changes []m.FieldChange) []string {
+	var types []string
+	updated := false
+	for _, change := range changes {
+		switch change.Field {
+		case "status":
+			switch change.NewValue {
+			case "cancelada":
+				types = append(types, events.ReservationCancelled)
+			case "sentada":
+				types = append(types, events.ReservationSeated)
+			case StatusNoShow:
+				types = append(types, events.ReservationNoShow)
+			default:
+				updated = true
+			}
+		case "tableid":
+			types = append(types, events.ReservationTableChanged)
+		default:
+			updated = true
+		}
+	}
+	if updated {
+		types = append(types, events.ReservationUpdated)
+	}
+	return types
+}
+
+// eventReservation es la reserva tal como viaja en los eventos. Se quitan
+// el historial, las notas internas del personal y los datos del pago del
+// depósito (identificador y enlace de pago), que no necesita ningún
+// consumidor. Tampoco lleva el código de confirmación ni los datos de
+// contacto del cliente: con ellos quien reciba el evento podría usar la
+// reserva por código. Las notificaciones los leen de la propia reserva.
+func eventReservation(reservation m.Reservation) m.Reservation {
+	reservation.History = nil
+	reservation.StaffNotes = ""
+	reservation.ConfirmationCode = ""
+	reservation.GuestName = ""
+	reservation.GuestEmail = ""
+	reservation.GuestPhone = ""
+	if reservation.Deposit != nil {
+		deposit := *reservation.Deposit
+		deposit.PaymentId = ""
+		deposit.CheckoutUrl = ""
+		reservation.Deposit = &deposit
+	}
+	return reservation
+}
+
+// enqueueEvents guarda en el outbox un evento de cada tipo para la reserva.
+// Debe llamarse con el contexto de la transacción del cambio.
+func enqueueEvents(ctx context.Context, types []string, reservation m.Reservation, changes []m.FieldChange) error {
+	if len(types) == 0 {
+		return nil
+	}
+	payload, err := json.Marshal(events.ReservationPayload{Reservation: eventReservation(reservation), Changes: changes})
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	docs := make([]interface{}, 0, len(types))
+	for _, eventType := range types {
+		docs = append(docs, m.OutboxEvent{
+			Type:         eventType,
+			AggregateId:  reservation.ID,
+			RestaurantId: reservation.RestaurantId,
+			Payload:      string(payload),
+			OccurredAt:   now,
+		})
+	}
+	collection := mongoClient.Database("reservations-db").Collection("outbox")
+	if _, err = collection.InsertMany(ctx, docs); err != nil {
+		slog.ErrorContext(ctx, "failed to insert outbox events", "error", err)
+		return err
+	}
+	return nil
+}
+
+// RelayOutbox publica los eventos pendientes en orden de aparición. Si uno
+// falla, se detiene para no desordenar los siguientes y lo reintenta en la
+// próxima pasada.
+func RelayOutbox(ctx context.Context, publisher events.Publisher) {
+	ctx, span := tracing.Start(ctx, "controllers.RelayOutbox")
+	defer span.End()
+
+	collection := mongoClient.Database("reservations-db").Collection("outbox")
+	opts := options.Find().SetSort(bson.D{{Key: "occurredat", Value: 1}, {Key: "_id", Value: 1}}).SetLimit(outboxBatchSize)
+	cursor, err := collection.Find(ctx, bson.M{"published": false}, opts)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to find outbox events", "error", err)
+		return
+	}
+	var pending []m.OutboxEvent
+	if err = cursor.All(ctx, &pending); err != nil {
+		slog.ErrorContext(ctx, "failed to decode outbox events", "error", err)
+		return
+	}
+
+	for _, outboxEvent := range pending {
+		objectID, err := primitive.ObjectIDFromHex(outboxEvent.ID)
+		if err != nil {
+			continue
+		}
+		err = publisher.Publish(ctx, events.Event{
+			ID:           outboxEvent.ID,
+			Type:         outboxEvent.Type,
+			AggregateID:  outboxEvent.AggregateId,
+			RestaurantID: outboxEvent.RestaurantId,
+			OccurredAt:   outboxEvent.OccurredAt,
+			Payload:      json.RawMessage(outboxEvent.Payload),
+		})
+		if err != nil {
+			slog.WarnContext(ctx, "failed to publish outbox event", "event_id", outboxEvent.ID, "type", outboxEvent.Type, "error", err)
+			_, updateErr := collection.UpdateOne(ctx, bson.M{"_id": objectID}, bson.M{
+				"$inc": bson.M{"attempts": 1},
+				"$set": bson.M{"lasterror": err.Error()},
+			})
+			if updateErr != nil {
+				slog.ErrorContext(ctx, "failed to record outbox failure", "event_id", outboxEvent.ID, "error", updateErr)
+			}
+			return
+		}
+
+		_, err = collection.UpdateOne(ctx, bson.M{"_id": objectID}, bson.M{
+			"$inc": bson.M{"attempts": 1},
+			"$set": bson.M{"published": true, "publishedat": time.Now(), "lasterror": ""},
+		})
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to mark outbox event as published", "event_id", outboxEvent.ID, "error", err)
+			return
+		}
+	}
+}
+
+// StartOutboxRelay publica periódicamente los eventos pendientes hasta que
+// se cancele ctx.
+func StartOutboxRelay(ctx context.Context, publisher events.Publisher, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				RelayOutbox(ctx, publisher)
+			}
+		}
+	}()
+}
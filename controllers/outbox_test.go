@@ -0,0 +1,166 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"slices"
+	"testing"
+	"time"
+
+	"ms-reservas/events"
+	m "ms-reservas/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func TestReservationEvents(t *testing.T) {
+	tests := []struct {
+		name    string
+		changes []m.FieldChange
+		want    []string
+	}{
+		{"no changes", nil, nil},
+		{"cancelled", []m.FieldChange{{Field: "status", NewValue: "cancelada"}}, []string{events.ReservationCancelled}},
+		{"seated", []m.FieldChange{{Field: "status", NewValue: "sentada"}}, []string{events.ReservationSeated}},
+		{"no show", []m.FieldChange{{Field: "status", NewValue: StatusNoShow}}, []string{events.ReservationNoShow}},
+		{"table and date", []m.FieldChange{{Field: "tableid"}, {Field: "reservationdate"}}, []string{events.ReservationTableChanged, events.ReservationUpdated}},
+		{"other status", []m.FieldChange{{Field: "status", NewValue: "confirmada"}}, []string{events.ReservationUpdated}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := reservationEvents(tt.changes); !slices.Equal(got, tt.want) {
+				t.Errorf("reservationEvents() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEventReservation(t *testing.T) {
+	deposit := &m.Deposit{AmountCents: 5000, PaymentId: "pay_1", CheckoutUrl: "https://pay.example/1", Status: DepositPending}
+	reservation := m.Reservation{
+		ID:               "r1",
+		ConfirmationCode: "K7M2QX",
+		GuestName:        "Ana Pérez",
+		GuestEmail:       "ana@example.com",
+		GuestPhone:       "+34600000000",
+		StaffNotes:       "regular, prefers the terrace",
+		Deposit:          deposit,
+		History:          []m.ReservationChange{{Version: 1}},
+	}
+
+	got := eventReservation(reservation)
+	if got.History != nil || got.StaffNotes != "" {
+		t.Errorf("event reservation kept history or staff notes: %+v", got)
+	}
+	if got.Deposit.PaymentId != "" || got.Deposit.CheckoutUrl != "" || got.Deposit.AmountCents != 5000 {
+		t.Errorf("event deposit = %+v, want the amount without payment details", got.Deposit)
+	}
+	if got.ConfirmationCode != "" || got.GuestName != "" || got.GuestEmail != "" || got.GuestPhone != "" {
+		t.Errorf("event reservation kept the confirmation code or guest contact: %+v", got)
+	}
+	if deposit.PaymentId != "pay_1" {
+		t.Errorf("eventReservation modified the original deposit")
+	}
+}
+
+// connectTestMongo usa el servidor de MONGODB_TEST_URI, que debe ser un
+// replica set para admitir transacciones. Sin la variable la prueba se
+// omite.
+func connectTestMongo(t *testing.T) context.Context {
+	t.Helper()
+	uri := os.Getenv("MONGODB_TEST_URI")
+	if uri == "" {
+		t.Skip("MONGODB_TEST_URI not set")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	t.Cleanup(cancel)
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+	if err != nil {
+		t.Fatalf("failed to connect to MongoDB: %v", err)
+	}
+	t.Cleanup(func() { client.Disconnect(context.Background()) })
+	SetMongoClient(client)
+	return ctx
+}
+
+type failingPublisher struct{}
+
+func (failingPublisher) Publish(context.Context, events.Event) error {
+	return errors.New("broker unavailable")
+}
+
+func TestOutboxRelay(t *testing.T) {
+	ctx := connectTestMongo(t)
+	outbox := mongoClient.Database("reservations-db").Collection("outbox")
+	reservation := m.Reservation{
+		ID:           primitive.NewObjectID().Hex(),
+		RestaurantId: "restaurant-test",
+		Status:       "sentada",
+		History:      []m.ReservationChange{{Version: 1}},
+	}
+	t.Cleanup(func() { outbox.DeleteMany(context.Background(), bson.M{"aggregateid": reservation.ID}) })
+
+	pending := func() int64 {
+		count, err := outbox.CountDocuments(ctx, bson.M{"aggregateid": reservation.ID, "published": false})
+		if err != nil {
+			t.Fatalf("failed to count outbox events: %v", err)
+		}
+		return count
+	}
+
+	// Un cambio que no se confirma no deja eventos
+	err := withTransaction(ctx, func(ctx context.Context) error {
+		if err := enqueueEvents(ctx, []string{events.ReservationCreated}, reservation, nil); err != nil {
+			return err
+		}
+		return errors.New("rollback")
+	})
+	if err == nil {
+		t.Fatal("expected the transaction to fail")
+	}
+	if n := pending(); n != 0 {
+		t.Fatalf("aborted transaction left %d outbox events", n)
+	}
+
+	err = withTransaction(ctx, func(ctx context.Context) error {
+		return enqueueEvents(ctx, []string{events.ReservationCreated, events.ReservationSeated}, reservation, nil)
+	})
+	if err != nil {
+		t.Fatalf("failed to enqueue events: %v", err)
+	}
+
+	// Si el publicador falla los eventos siguen pendientes
+	RelayOutbox(ctx, failingPublisher{})
+	if n := pending(); n != 2 {
+		t.Fatalf("pending events after a failed publish = %d, want 2", n)
+	}
+
+	publisher := events.NewMemoryPublisher()
+	RelayOutbox(ctx, publisher)
+	RelayOutbox(ctx, publisher)
+	if n := pending(); n != 0 {
+		t.Fatalf("pending events after relaying = %d, want 0", n)
+	}
+
+	var published []events.Event
+	for _, event := range publisher.Events() {
+		if event.AggregateID == reservation.ID {
+			published = append(published, event)
+		}
+	}
+	if len(published) != 2 || published[0].Type != events.ReservationCreated || published[1].Type != events.ReservationSeated {
+		t.Fatalf("published events = %+v, want ReservationCreated and ReservationSeated once each", published)
+	}
+	var payload events.ReservationPayload
+	if err = json.Unmarshal(published[0].Payload, &payload); err != nil {
+		t.Fatalf("invalid payload: %v", err)
+	}
+	if payload.Reservation.ID != reservation.ID || payload.Reservation.History != nil {
+		t.Errorf("payload reservation = %+v, want the reservation without history", payload.Reservation)
+	}
+}
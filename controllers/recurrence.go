@@ -0,0 +1,213 @@
+package controllers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxOccurrences limita cuántas reservas puede generar una serie.
+const maxOccurrences = 104
+
+// maxRecurrenceYears limita hasta dónde se buscan fechas de una serie, para
+// que una regla que no llega a COUNT no recorra el calendario sin fin.
+const maxRecurrenceYears = 10
+
+// RecurrenceRule es el subconjunto de RRULE (RFC 5545) que admite el
+// servicio: FREQ=DAILY|WEEKLY|MONTHLY, INTERVAL, COUNT, UNTIL y BYDAY.
+// Toda regla debe acotarse con COUNT o UNTIL.
+type RecurrenceRule struct {
+	Freq     string
+	Interval int
+	Count    int
+	Until    time.Time
+	ByDay    []time.Weekday
+}
+
+var rruleWeekdays = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+// ParseRecurrenceRule interpreta una regla como "FREQ=WEEKLY;BYDAY=TU;COUNT=10".
+// Se acepta el prefijo "RRULE:".
+func ParseRecurrenceRule(rule string) (*RecurrenceRule, error) {
+	rule = strings.TrimPrefix(strings.TrimSpace(rule), "RRULE:")
+	if rule == "" {
+		return nil, fmt.Errorf("recurrence rule is required")
+	}
+
+	r := &RecurrenceRule{Interval: 1}
+	for _, part := range strings.Split(rule, ";") {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok || value == "" {
+			return nil, fmt.Errorf("invalid rule part %q", part)
+		}
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			r.Freq = strings.ToUpper(value)
+			if r.Freq != "DAILY" && r.Freq != "WEEKLY" && r.Freq != "MONTHLY" {
+				return nil, fmt.Errorf("unsupported FREQ %q, expected DAILY, WEEKLY or MONTHLY", value)
+			}
+		case "INTERVAL":
+			interval, err := strconv.Atoi(value)
+			if err != nil || interval <= 0 {
+				return nil, fmt.Errorf("invalid INTERVAL %q", value)
+			}
+			r.Interval = interval
+		case "COUNT":
+			count, err := strconv.Atoi(value)
+			if err != nil || count <= 0 {
+				return nil, fmt.Errorf("invalid COUNT %q", value)
+			}
+			r.Count = count
+		case "UNTIL":
+			until, err := parseRRuleDate(value)
+			if err != nil {
+				return nil, err
+			}
+			r.Until = until
+		case "BYDAY":
+			for _, day := range strings.Split(value, ",") {
+				weekday, ok := rruleWeekdays[strings.ToUpper(day)]
+				if !ok {
+					return nil, fmt.Errorf("invalid BYDAY value %q", day)
+				}
+				r.ByDay = append(r.ByDay, weekday)
+			}
+		default:
+			return nil, fmt.Errorf("unsupported rule part %q", key)
+		}
+	}
+
+	if r.Freq == "" {
+		return nil, fmt.Errorf("FREQ is required")
+	}
+	if r.Count == 0 && r.Until.IsZero() {
+		return nil, fmt.Errorf("COUNT or UNTIL is required")
+	}
+	if r.Count > 0 && !r.Until.IsZero() {
+		return nil, fmt.Errorf("COUNT and UNTIL cannot be combined")
+	}
+	if r.Count > maxOccurrences {
+		return nil, fmt.Errorf("COUNT cannot exceed %d", maxOccurrences)
+	}
+	if len(r.ByDay) > 0 && r.Freq == "MONTHLY" {
+		return nil, fmt.Errorf("BYDAY is only supported with DAILY or WEEKLY")
+	}
+	// Con un intervalo diario múltiplo de 7 todas las fechas caen en el mismo
+	// día de la semana, así que BYDAY podría no coincidir nunca
+	if len(r.ByDay) > 0 && r.Freq == "DAILY" && r.Interval%7 == 0 {
+		return nil, fmt.Errorf("BYDAY with a DAILY INTERVAL multiple of 7 is not supported, use FREQ=WEEKLY")
+	}
+	return r, nil
+}
+
+func parseRRuleDate(value string) (time.Time, error) {
+	for _, layout := range []string{"20060102T150405Z", "20060102T150405", "20060102"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid UNTIL %q, expected YYYYMMDD", value)
+}
+
+// Occurrences devuelve las fechas de la serie a partir de start (incluida
+// si cumple la regla), en orden cronológico.
+func (r *RecurrenceRule) Occurrences(start time.Time) ([]time.Time, error) {
+	start = time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+	horizon := start.AddDate(maxRecurrenceYears, 0, 0)
+	errHorizon := fmt.Errorf("recurrence rule does not complete within %d years", maxRecurrenceYears)
+	var dates []time.Time
+
+	done := func(date time.Time) bool {
+		if r.Count > 0 {
+			return len(dates) >= r.Count
+		}
+		return date.After(r.Until)
+	}
+
+	switch r.Freq {
+	case "DAILY":
+		for date := start; !done(date); date = date.AddDate(0, 0, r.Interval) {
+			if date.After(horizon) {
+				return nil, errHorizon
+			}
+			if r.matchesDay(date) {
+				dates = append(dates, date)
+			}
+			if len(dates) > maxOccurrences {
+				break
+			}
+		}
+	case "WEEKLY":
+		// Semana que empieza en lunes, como WKST=MO por defecto
+		weekStart := start.AddDate(0, 0, -((int(start.Weekday()) + 6) % 7))
+		for ; ; weekStart = weekStart.AddDate(0, 0, 7*r.Interval) {
+			if weekStart.After(horizon) {
+				return nil, errHorizon
+			}
+			finished := false
+			for offset := 0; offset < 7; offset++ {
+				date := weekStart.AddDate(0, 0, offset)
+				if date.Before(start) {
+					continue
+				}
+				if done(date) {
+					finished = true
+					break
+				}
+				// Sin BYDAY se repite el mismo día de la semana que la fecha inicial
+				if len(r.ByDay) == 0 && date.Weekday() == start.Weekday() ||
+					len(r.ByDay) > 0 && r.matchesDay(date) {
+					dates = append(dates, date)
+				}
+			}
+			if finished || len(dates) > maxOccurrences {
+				break
+			}
+		}
+	case "MONTHLY":
+		for i := 0; ; i += r.Interval {
+			first := time.Date(start.Year(), start.Month()+time.Month(i), 1, 0, 0, 0, 0, time.UTC)
+			if first.After(horizon) {
+				return nil, errHorizon
+			}
+			date := first.AddDate(0, 0, start.Day()-1)
+			if date.Month() != first.Month() {
+				// El mes no tiene ese día (p. ej. 31): se omite, como en RFC 5545
+				if !r.Until.IsZero() && first.After(r.Until) {
+					break
+				}
+				continue
+			}
+			if done(date) || len(dates) > maxOccurrences {
+				break
+			}
+			dates = append(dates, date)
+		}
+	}
+
+	if len(dates) > maxOccurrences {
+		return nil, fmt.Errorf("recurrence rule generates more than %d occurrences", maxOccurrences)
+	}
+	return dates, nil
+}
+
+func (r *RecurrenceRule) matchesDay(date time.Time) bool {
+	if len(r.ByDay) == 0 {
+		return true
+	}
+	for _, weekday := range r.ByDay {
+		if date.Weekday() == weekday {
+			return true
+		}
+	}
+	return false
+}
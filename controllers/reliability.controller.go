@@ -0,0 +1,143 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"time"
+
+	m "ms-reservas/models"
+	pb "ms-reservas/protos_pb/proto"
+	"ms-reservas/tracing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// EnsureReliabilityIndexes crea el índice único por cliente.
+func EnsureReliabilityIndexes(ctx context.Context) error {
+	collection := mongoClient.Database("reservations-db").Collection("guest_reliability")
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "userid", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to create reliability indexes", "error", err)
+	}
+	return err
+}
+
+// ReliabilityScore puntúa de 0 a 100 la asistencia del cliente. Cada no
+// presentación pesa el doble que una cancelación tardía; las cancelaciones
+// con antelación no penalizan. Un cliente sin historial puntúa 100.
+func ReliabilityScore(reliability m.GuestReliability) int {
+	good := float64(reliability.Completed + 1)
+	bad := float64(2*reliability.NoShows + reliability.LateCancellations)
+	return int(math.Round(100 * good / (good + bad)))
+}
+
+// GET RELIABILITY
+func GetGuestReliabilityHandler(ctx context.Context, req *pb.GetGuestReliabilityRequest) (*pb.GuestReliability, error) {
+	ctx, span := tracing.Start(ctx, "controllers.GetGuestReliabilityHandler")
+	defer span.End()
+
+	reliability, err := GetGuestReliability(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.GuestReliability{
+		UserId:            req.UserId,
+		Score:             int32(ReliabilityScore(reliability)),
+		Completed:         int32(reliability.Completed),
+		NoShows:           int32(reliability.NoShows),
+		Cancellations:     int32(reliability.Cancellations),
+		LateCancellations: int32(reliability.LateCancellations),
+	}, nil
+}
+
+// GetGuestReliability devuelve los contadores del cliente, vacíos si aún no
+// tiene historial.
+func GetGuestReliability(ctx context.Context, userID string) (m.GuestReliability, error) {
+	ctx, span := tracing.Start(ctx, "controllers.GetGuestReliability")
+	defer span.End()
+
+	if userID == "" {
+		return m.GuestReliability{}, fmt.Errorf("userID is required")
+	}
+	collection := mongoClient.Database("reservations-db").Collection("guest_reliability")
+	reliability := m.GuestReliability{UserId: userID}
+	err := collection.FindOne(ctx, bson.M{"userid": userID}).Decode(&reliability)
+	if err != nil && err != mongo.ErrNoDocuments {
+		slog.ErrorContext(ctx, "failed to find guest reliability", "error", err)
+		return m.GuestReliability{}, err
+	}
+	return reliability, nil
+}
+
+// recordReliability actualiza los contadores del cliente cuando su reserva
+// pasa a status. Las cancelaciones cuentan como tardías según la política
+// aplicada al cancelar. Un fallo se registra pero no deshace el cambio de
+// estado.
+func recordReliability(ctx context.Context, reservation m.Reservation, status string) {
+	ctx, span := tracing.Start(ctx, "controllers.recordReliability")
+	defer span.End()
+
+	if anonymousWalkIn(reservation) {
+		return
+	}
+
+	inc := bson.M{}
+	switch status {
+	case "completada":
+		inc["completed"] = 1
+	case StatusNoShow:
+		inc["noshows"] = 1
+	case "cancelada":
+		inc["cancellations"] = 1
+		if reservation.Cancellation != nil && reservation.Cancellation.Late {
+			inc["latecancellations"] = 1
+		}
+	default:
+		return
+	}
+
+	collection := mongoClient.Database("reservations-db").Collection("guest_reliability")
+	_, err := collection.UpdateOne(ctx, bson.M{"userid": reservation.UserId}, bson.M{
+		"$inc": inc,
+		"$set": bson.M{"updateat": time.Now()},
+	}, options.Update().SetUpsert(true))
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to update guest reliability", "user_id", reservation.UserId, "error", err)
+	}
+}
+
+// applyReliabilityPolicy aplica las políticas de fiabilidad del restaurante
+// a una reserva nueva. Devuelve el estado con el que debe crearse o, si el
+// cliente no puede reservar, el motivo del rechazo.
+func applyReliabilityPolicy(ctx context.Context, restaurant *m.Restaurant, userID string, guestCount int, status string) (string, string, error) {
+	ctx, span := tracing.Start(ctx, "controllers.applyReliabilityPolicy")
+	defer span.End()
+
+	policies := restaurant.Policies
+	if policies.ReliabilityBlockBelow == 0 && policies.ReliabilityMaxGuestsBelow == 0 && policies.ReliabilityConfirmBelow == 0 {
+		return status, "", nil
+	}
+	reliability, err := GetGuestReliability(ctx, userID)
+	if err != nil {
+		return "", "", err
+	}
+	score := ReliabilityScore(reliability)
+
+	if score < policies.ReliabilityBlockBelow {
+		return "", "Online booking is not available for this guest, please contact the restaurant", nil
+	}
+	if score < policies.ReliabilityMaxGuestsBelow && guestCount > policies.ReliabilityMaxGuests {
+		return "", fmt.Sprintf("guestCount exceeds the limit of %d for this guest", policies.ReliabilityMaxGuests), nil
+	}
+	if score < policies.ReliabilityConfirmBelow && status == "confirmada" {
+		return StatusPendingConfirmation, "", nil
+	}
+	return status, "", nil
+}
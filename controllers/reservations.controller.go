@@ -3,11 +3,17 @@ package controllers
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
+	"net/mail"
 	"time"
 
+	"ms-reservas/auth"
+	"ms-reservas/events"
+	"ms-reservas/metrics"
 	m "ms-reservas/models"
+	"ms-reservas/notifications"
 	pb "ms-reservas/protos_pb/proto"
+	"ms-reservas/tracing"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -16,69 +22,242 @@ import (
 
 var mongoClient *mongo.Client
 
+// StatusNoShow marca una reserva cuyo cliente no se presentó.
+const StatusNoShow = "no_presentada"
+
+// StatusPendingConfirmation marca una reserva que el cliente debe
+// reconfirmar. Ocupa la mesa igual que una confirmada.
+const StatusPendingConfirmation = "por_confirmar"
+
+// StatusPendingPayment marca una reserva que espera el pago de su depósito.
+// Ocupa la mesa hasta que se paga o vence el plazo.
+const StatusPendingPayment = "pendiente_pago"
+
+// awaitingGuest indica si la reserva sigue esperando la llegada del cliente.
+func awaitingGuest(status string) bool {
+	return status == "confirmada" || status == StatusPendingConfirmation
+}
+
+// Estados válidos de una reserva
+var validStatuses = map[string]bool{
+	"confirmada":              true,
+	"sentada":                 true,
+	"cancelada":               true,
+	"completada":              true,
+	StatusNoShow:              true,
+	StatusPendingConfirmation: true,
+	StatusPendingPayment:      true,
+}
+
+const invalidStatusMessage = "invalid status, expected one of: confirmada, por_confirmar, pendiente_pago, sentada, cancelada, completada, no_presentada"
+
+// notDeleted excluye de una consulta las reservas borradas. Las reservas
+// anteriores al borrado lógico no tienen el campo, por eso no se compara
+// con false.
+var notDeleted = bson.M{"$ne": true}
+
+// reservationRetention lee RESERVATION_RETENTION_DAYS, el tiempo que una
+// reserva borrada puede restaurarse antes de eliminarse definitivamente.
+func reservationRetention() time.Duration {
+	return envDays("RESERVATION_RETENTION_DAYS", 30*24*time.Hour)
+}
+
+// Origen de una reserva
+const (
+	SourceBooking = "reserva"
+	SourceWalkIn  = "walk-in"
+)
+
 func SetMongoClient(client *mongo.Client) {
 	mongoClient = client
+	jobRunner = newJobRunner(client)
 }
 
 // CREATE
-func CreateRes(reservation m.Reservation) error {
+func CreateRes(ctx context.Context, reservation m.Reservation) (string, error) {
+	created, err := createReservation(ctx, reservation)
+	return created.ID, err
+}
+
+// createReservation valida y guarda la reserva y devuelve la reserva
+// creada, con su ID y su código de confirmación.
+func createReservation(ctx context.Context, reservation m.Reservation) (m.Reservation, error) {
+	ctx, span := tracing.Start(ctx, "controllers.createReservation")
+	defer span.End()
+
+	reservation, err := prepareReservation(ctx, reservation)
+	if err != nil {
+		return m.Reservation{}, err
+	}
+	var created m.Reservation
+	err = withTransaction(ctx, func(ctx context.Context) error {
+		created, err = insertReservation(ctx, reservation)
+		return err
+	})
+	if err != nil {
+		return m.Reservation{}, err
+	}
+	reservationCreated(ctx, created)
+	return created, nil
+}
+
+// prepareReservation valida una reserva nueva y le asigna el código de
+// confirmación y la versión inicial.
+func prepareReservation(ctx context.Context, reservation m.Reservation) (m.Reservation, error) {
+	if reservation.RestaurantId == "" {
+		return m.Reservation{}, fmt.Errorf("restaurantID is required")
+	}
 	if reservation.UserId == "" {
-		return fmt.Errorf("userID is required")
+		return m.Reservation{}, fmt.Errorf("userID is required")
 	}
 	if reservation.TableId == "" {
-		return fmt.Errorf("tableID is required")
+		return m.Reservation{}, fmt.Errorf("tableID is required")
 	}
 	if reservation.ReservationDate == "" {
-		return fmt.Errorf("reservationDate is required")
+		return m.Reservation{}, fmt.Errorf("reservationDate is required")
 	}
 	if reservation.ReservationTime == "" {
-		return fmt.Errorf("reservationTime is required")
+		return m.Reservation{}, fmt.Errorf("reservationTime is required")
 	}
 	if reservation.GuestCount == 0 {
-		return fmt.Errorf("guestCount is required")
+		return m.Reservation{}, fmt.Errorf("guestCount is required")
 	}
 	if reservation.Status == "" {
-		return fmt.Errorf("status is required")
-	}
-
-	validStatuses := map[string]bool{
-		"confirmada": true,
-		"cancelada":  true,
-		"completada": true,
+		return m.Reservation{}, fmt.Errorf("status is required")
 	}
 	if !validStatuses[reservation.Status] {
-		return fmt.Errorf("invalid status, expected one of: confirmada, cancelada, completada")
+		return m.Reservation{}, fmt.Errorf(invalidStatusMessage)
+	}
+	if reservation.GuestEmail != "" {
+		if _, err := mail.ParseAddress(reservation.GuestEmail); err != nil {
+			return m.Reservation{}, fmt.Errorf("invalid guest email")
+		}
+	}
+	if reservation.Language != "" && !notifications.SupportedLanguage(reservation.Language) {
+		return m.Reservation{}, fmt.Errorf("invalid language, expected one of %v", notifications.Languages)
+	}
+	if err := validateSpecialRequests(reservation); err != nil {
+		return m.Reservation{}, err
 	}
 
 	const dateFormat = "02-01-2006"
 	_, err := time.Parse(dateFormat, reservation.ReservationDate)
 	if err != nil {
-		return fmt.Errorf("invalid date format, expected dd-mm-yyyy")
+		return m.Reservation{}, fmt.Errorf("invalid date format, expected dd-mm-yyyy")
 	}
 
 	const timeFormat = "15:04"
 	reservationTime, err := time.Parse(timeFormat, reservation.ReservationTime)
 	if err != nil {
-		return fmt.Errorf("invalid time format, expected HH:MM")
+		return m.Reservation{}, fmt.Errorf("invalid time format, expected HH:MM")
 	}
 
-	fmt.Println(reservationTime.Minute())
-
 	if reservationTime.Minute() != 0 {
-		return fmt.Errorf("reservation time must be end in 00")
+		return m.Reservation{}, fmt.Errorf("reservation time must be end in 00")
+	}
+
+	restaurant, err := GetRestaurantByID(ctx, reservation.RestaurantId)
+	if err != nil {
+		return m.Reservation{}, fmt.Errorf("restaurant not found")
+	}
+	if _, err = GetRestaurantTable(ctx, reservation.RestaurantId, reservation.TableId); err != nil {
+		return m.Reservation{}, err
+	}
+	if reservation.Source != SourceWalkIn {
+		err = ValidateRestaurantSlot(restaurant, reservation.ReservationDate, reservation.ReservationTime, reservation.GuestCount)
+		if err != nil {
+			return m.Reservation{}, err
+		}
 	}
 
+	if reservation.ConfirmationCode == "" {
+		reservation.ConfirmationCode, err = newConfirmationCode()
+		if err != nil {
+			return m.Reservation{}, err
+		}
+	}
+
+	reservation.Version = 1
+	return reservation, nil
+}
+
+// insertReservation guarda la reserva y su evento ReservationCreated. Debe
+// llamarse dentro de withTransaction, que puede reintentarla: reservation
+// no se modifica, cada intento la inserta sin _id y devuelve una copia con
+// el ID asignado.
+func insertReservation(ctx context.Context, reservation m.Reservation) (m.Reservation, error) {
 	collection := mongoClient.Database("reservations-db").Collection("reservations")
-	_, err = collection.InsertOne(context.TODO(), reservation)
+	result, err := collection.InsertOne(ctx, reservation)
 	if err != nil {
-		log.Printf("failed to insert reservation: %v", err)
-		return err
+		slog.ErrorContext(ctx, "failed to insert reservation", "error", err)
+		return m.Reservation{}, err
 	}
-	return nil
+	reservation.ID = result.InsertedID.(primitive.ObjectID).Hex()
+	if err = enqueueEvents(ctx, []string{events.ReservationCreated}, reservation, nil); err != nil {
+		return m.Reservation{}, err
+	}
+	return reservation, nil
 }
 
-func CreateReservationHandler(req *pb.CreateReservationRequest) (*pb.Response, error) {
-	exists, err := ReservationExists(req.TableId, req.ReservationDate, req.ReservationTime)
+// reservationCreated ejecuta los efectos de una reserva nueva que no forman
+// parte de la transacción (métricas, auditoría, jobs y perfil del cliente).
+// Se llama una sola vez, después de confirmarla.
+func reservationCreated(ctx context.Context, reservation m.Reservation) {
+	metrics.ReservationCreated(reservation.Status, reservation.Source)
+	recordAudit(ctx, AuditEntityReservation, reservation.ID, reservation.RestaurantId, AuditCreate, nil, reservation)
+	scheduleReservationJobs(ctx, reservation)
+	linkGuestProfile(ctx, reservation)
+}
+
+func CreateReservationHandler(ctx context.Context, req *pb.CreateReservationRequest) (*pb.Response, error) {
+	ctx, span := tracing.Start(ctx, "controllers.CreateReservationHandler")
+	defer span.End()
+
+	restaurant, err := GetRestaurantByID(ctx, req.RestaurantId)
+	if err != nil {
+		return &pb.Response{Message: "Restaurant not found", Success: false}, err
+	}
+	reservationStatus, rejection, err := applyReliabilityPolicy(ctx, restaurant, req.UserId, int(req.GuestCount), req.Status)
+	if err != nil {
+		return &pb.Response{Message: "Failed to check guest reliability", Success: false}, err
+	}
+	if rejection != "" {
+		return &pb.Response{Message: rejection, Success: false}, nil
+	}
+	depositCents := 0
+	if awaitingGuest(reservationStatus) {
+		depositCents = requiredDeposit(restaurant, req.ReservationDate, int(req.GuestCount))
+	}
+
+	tableID := req.TableId
+	if req.HoldToken != "" {
+		// La reserva confirma un bloqueo temporal: la franja ya está apartada
+		hold, err := GetActiveHold(ctx, req.HoldToken)
+		if err != nil {
+			return &pb.Response{Message: "Hold not found or expired", Success: false}, err
+		}
+		if hold.RestaurantId != req.RestaurantId ||
+			hold.UserId != req.UserId ||
+			hold.GuestCount != int(req.GuestCount) ||
+			(tableID != "" && tableID != hold.TableId) ||
+			req.ReservationDate != hold.ReservationDate ||
+			req.ReservationTime != hold.ReservationTime {
+			return &pb.Response{Message: "Reservation does not match the held slot", Success: false}, nil
+		}
+		tableID = hold.TableId
+	} else if tableID == "" {
+		// Sin mesa indicada se asigna automáticamente la más ajustada al grupo
+		table, err := AssignTable(ctx, req.RestaurantId, req.ReservationDate, []string{req.ReservationTime}, int(req.GuestCount))
+		if err != nil {
+			return &pb.Response{Message: "Failed to assign table", Success: false}, err
+		}
+		if table == nil {
+			return &pb.Response{Message: "No table available for this date and party size", Success: false}, nil
+		}
+		tableID = table.ID
+	}
+
+	exists, err := slotTaken(ctx, tableID, req.ReservationDate, req.ReservationTime, req.HoldToken)
 	if err != nil {
 		return &pb.Response{Message: "Failed to check existing reservations", Success: false}, err
 	}
@@ -87,227 +266,650 @@ func CreateReservationHandler(req *pb.CreateReservationRequest) (*pb.Response, e
 	}
 
 	reservation := m.Reservation{
-		UserId:          req.UserId,
-		TableId:         req.TableId,
-		ReservationDate: req.ReservationDate,
-		ReservationTime: req.ReservationTime,
-		GuestCount:      int(req.GuestCount),
-		Status:          req.Status,
-		CreateAt:        time.Now(),
+		RestaurantId:        req.RestaurantId,
+		UserId:              req.UserId,
+		TableId:             tableID,
+		ReservationDate:     req.ReservationDate,
+		ReservationTime:     req.ReservationTime,
+		GuestCount:          int(req.GuestCount),
+		Status:              reservationStatus,
+		Source:              SourceBooking,
+		GuestName:           req.GuestName,
+		GuestEmail:          req.GuestEmail,
+		GuestPhone:          req.GuestPhone,
+		Language:            req.Language,
+		Occasion:            req.Occasion,
+		DietaryRestrictions: normalizeDietaryRestrictions(req.DietaryRestrictions),
+		Notes:               req.Notes,
+		StaffNotes:          req.StaffNotes,
+		CreateAt:            time.Now(),
+	}
+	if depositCents > 0 {
+		if err = requestDeposit(ctx, restaurant, &reservation, depositCents); err != nil {
+			return &pb.Response{Message: err.Error(), Success: false}, nil
+		}
 	}
-	err = CreateRes(reservation)
+	created, err := createReservation(ctx, reservation)
 	if err != nil {
+		settleDeposit(ctx, reservation)
 		return &pb.Response{Message: "Failed to create reservation", Success: false}, err
 	}
 
-	err = UpdateTableIsReserved(req.TableId, true)
+	err = UpdateTableIsReserved(ctx, tableID, true)
 	if err != nil {
 		return &pb.Response{Message: "Failed to update table status", Success: false}, err
 	}
 
-	return &pb.Response{Message: "Reservation created successfully", Success: true}, nil
+	if req.HoldToken != "" {
+		if err = ReleaseHold(ctx, req.HoldToken); err != nil {
+			return &pb.Response{Message: "Failed to release hold", Success: false}, err
+		}
+	}
+
+	if created.Status == StatusPendingPayment {
+		return &pb.Response{Message: "Reservation created, pay the deposit to confirm it", Success: true, ConfirmationCode: created.ConfirmationCode}, nil
+	}
+	return &pb.Response{Message: "Reservation created successfully", Success: true, ConfirmationCode: created.ConfirmationCode}, nil
 }
 
 // GET BY ID
-func GetByIdHandler(req *pb.GetReservationByIDRequest) (*pb.Reservation, error) {
+func GetByIdHandler(ctx context.Context, req *pb.GetReservationByIDRequest) (*pb.Reservation, error) {
+	ctx, span := tracing.Start(ctx, "controllers.GetByIdHandler")
+	defer span.End()
+
 	id := req.Id
-	reservation, err := GetReservationByID(id)
+	reservation, err := GetReservationByID(ctx, req.RestaurantId, id)
 	if err != nil {
 		return nil, err
 	}
-	return &pb.Reservation{
-		Id:              reservation.ID,
-		UserId:          reservation.UserId,
-		TableId:         reservation.TableId,
-		ReservationDate: reservation.ReservationDate,
-		GuestCount:      int32(reservation.GuestCount),
-		Status:          reservation.Status,
-	}, nil
+	guests := guestSummaries(ctx, []m.Reservation{*reservation})
+	return toPbReservation(*reservation, guests[reservation.UserId]), nil
 }
 
-func GetReservationByID(id string) (*m.Reservation, error) {
+func GetReservationByID(ctx context.Context, restaurantID, id string) (*m.Reservation, error) {
+	ctx, span := tracing.Start(ctx, "controllers.GetReservationByID")
+	defer span.End()
+
+	if restaurantID == "" {
+		return nil, fmt.Errorf("restaurantID is required")
+	}
 	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		log.Printf("invalid id format: %v", err)
+		slog.WarnContext(ctx, "invalid id format", "error", err)
 		return nil, err
 	}
 
 	collection := mongoClient.Database("reservations-db").Collection("reservations")
 	var reservation m.Reservation
-	err = collection.FindOne(context.TODO(), bson.M{"_id": objectID}).Decode(&reservation)
+	err = collection.FindOne(ctx, bson.M{"_id": objectID, "restaurantid": restaurantID, "deleted": notDeleted}).Decode(&reservation)
 	if err != nil {
-		log.Printf("Failed to find reservation: %v", err)
+		slog.ErrorContext(ctx, "failed to find reservation", "error", err)
 		return nil, err
 	}
 	return &reservation, nil
 }
 
 // GET BY USER ID
-func GetReservationsByUserIDHandler(req *pb.GetReservationsByUserIDRequest) (*pb.Reservations, error) {
+func GetReservationsByUserIDHandler(ctx context.Context, req *pb.GetReservationsByUserIDRequest) (*pb.Reservations, error) {
+	ctx, span := tracing.Start(ctx, "controllers.GetReservationsByUserIDHandler")
+	defer span.End()
+
 	userID := req.UserId
-	reservations, err := GetReservationsByUserID(userID)
+	reservations, err := GetReservationsByUserID(ctx, req.RestaurantId, userID)
 	if err != nil {
 		return nil, err
 	}
+	guests := guestSummaries(ctx, reservations)
 	var pbReservations []*pb.Reservation
 	for _, reservation := range reservations {
-		pbReservations = append(pbReservations, &pb.Reservation{
-			Id:              reservation.ID,
-			UserId:          reservation.UserId,
-			TableId:         reservation.TableId,
-			ReservationDate: reservation.ReservationDate,
-			GuestCount:      int32(reservation.GuestCount),
-			Status:          reservation.Status,
-		})
+		pbReservations = append(pbReservations, toPbReservation(reservation, guests[reservation.UserId]))
 	}
 	return &pb.Reservations{Reservations: pbReservations}, nil
 }
 
-func GetReservationsByUserID(userID string) ([]m.Reservation, error) {
+// GetReservationsByUserID devuelve las reservas del usuario; si se indica
+// restaurantID, solo las de esa sucursal.
+func GetReservationsByUserID(ctx context.Context, restaurantID, userID string) ([]m.Reservation, error) {
+	ctx, span := tracing.Start(ctx, "controllers.GetReservationsByUserID")
+	defer span.End()
+
+	filter := bson.M{"userid": userID, "deleted": notDeleted}
+	if restaurantID != "" {
+		filter["restaurantid"] = restaurantID
+	}
+
 	collection := mongoClient.Database("reservations-db").Collection("reservations")
-	cursor, err := collection.Find(context.TODO(), bson.M{"userid": userID})
+	cursor, err := collection.Find(ctx, filter)
 	if err != nil {
-		log.Printf("Failed to find reservations: %v", err)
+		slog.ErrorContext(ctx, "failed to find reservations", "error", err)
 		return nil, err
 	}
 	var reservations []m.Reservation
-	if err = cursor.All(context.TODO(), &reservations); err != nil {
-		log.Printf("Failed to decode reservations: %v", err)
+	if err = cursor.All(ctx, &reservations); err != nil {
+		slog.ErrorContext(ctx, "failed to decode reservations", "error", err)
 		return nil, err
 	}
 	return reservations, nil
 }
 
 // GET BY DATE
-func GetReservationsByDateHandler(req *pb.GetReservationsByDateRequest) (*pb.Reservations, error) {
+func GetReservationsByDateHandler(ctx context.Context, req *pb.GetReservationsByDateRequest) (*pb.Reservations, error) {
+	ctx, span := tracing.Start(ctx, "controllers.GetReservationsByDateHandler")
+	defer span.End()
+
 	date := req.ReservationDate
-	reservations, err := GetReservationsByDate(date)
+	reservations, err := GetReservationsByDate(ctx, req.RestaurantId, date)
 	if err != nil {
 		return nil, err
 	}
+	guests := guestSummaries(ctx, reservations)
 	var pbReservations []*pb.Reservation
 	for _, reservation := range reservations {
-		pbReservations = append(pbReservations, &pb.Reservation{
-			Id:              reservation.ID,
-			UserId:          reservation.UserId,
-			TableId:         reservation.TableId,
-			ReservationDate: reservation.ReservationDate,
-			GuestCount:      int32(reservation.GuestCount),
-			Status:          reservation.Status,
-			CreateAt:        reservation.CreateAt.Format(time.RFC3339),
-			UpdateAt:        reservation.UpdateAt.Format(time.RFC3339),
-		})
+		pbReservations = append(pbReservations, toPbReservation(reservation, guests[reservation.UserId]))
 	}
 	return &pb.Reservations{Reservations: pbReservations}, nil
 }
 
-func GetReservationsByDate(date string) ([]m.Reservation, error) {
+func GetReservationsByDate(ctx context.Context, restaurantID, date string) ([]m.Reservation, error) {
+	ctx, span := tracing.Start(ctx, "controllers.GetReservationsByDate")
+	defer span.End()
+
+	if restaurantID == "" {
+		return nil, fmt.Errorf("restaurantID is required")
+	}
+
 	collection := mongoClient.Database("reservations-db").Collection("reservations")
-	cursor, err := collection.Find(context.TODO(), bson.M{"reservationdate": date})
+	cursor, err := collection.Find(ctx, bson.M{"restaurantid": restaurantID, "reservationdate": date, "deleted": notDeleted})
 	if err != nil {
-		log.Printf("failed to find reservations: %v", err)
+		slog.ErrorContext(ctx, "failed to find reservations", "error", err)
 		return nil, err
 	}
 	var reservations []m.Reservation
-	if err = cursor.All(context.TODO(), &reservations); err != nil {
-		log.Printf("failed to decode reservations: %v", err)
+	if err = cursor.All(ctx, &reservations); err != nil {
+		slog.ErrorContext(ctx, "failed to decode reservations", "error", err)
 		return nil, err
 	}
 	return reservations, nil
 }
 
+// toPbReservation convierte la reserva con todos sus campos, junto al
+// resumen del perfil del cliente si lo tiene.
+func toPbReservation(reservation m.Reservation, guest *pb.GuestSummary) *pb.Reservation {
+	return &pb.Reservation{
+		Id:                  reservation.ID,
+		RestaurantId:        reservation.RestaurantId,
+		UserId:              reservation.UserId,
+		TableId:             reservation.TableId,
+		ReservationDate:     reservation.ReservationDate,
+		ReservationTime:     reservation.ReservationTime,
+		GuestCount:          int32(reservation.GuestCount),
+		Status:              reservation.Status,
+		Source:              reservation.Source,
+		ConfirmationCode:    reservation.ConfirmationCode,
+		SeriesId:            reservation.SeriesId,
+		Version:             int32(reservation.Version),
+		GuestName:           reservation.GuestName,
+		GuestEmail:          reservation.GuestEmail,
+		GuestPhone:          reservation.GuestPhone,
+		Language:            reservation.Language,
+		Cancellation:        toPbCancellationOutcome(reservation.Cancellation),
+		Deposit:             toPbDeposit(reservation.Deposit),
+		Occasion:            reservation.Occasion,
+		DietaryRestrictions: reservation.DietaryRestrictions,
+		Notes:               reservation.Notes,
+		StaffNotes:          reservation.StaffNotes,
+		Guest:               guest,
+		CreateAt:            reservation.CreateAt.Format(time.RFC3339),
+		UpdateAt:            reservation.UpdateAt.Format(time.RFC3339),
+		SeatedAt:            reservation.SeatedAt.Format(time.RFC3339),
+	}
+}
+
 // UPDATE
-func UpdateReservationHandler(req *pb.UpdateReservationRequest) (*pb.Response, error) {
+func UpdateReservationHandler(ctx context.Context, req *pb.UpdateReservationRequest) (*pb.Response, error) {
+	ctx, span := tracing.Start(ctx, "controllers.UpdateReservationHandler")
+	defer span.End()
+
 	id := req.Id
 	update := bson.M{}
-	if req.TableId != "" {
-		update["tableid"] = req.TableId
+	if req.TableId != "" || req.ReservationDate != "" || req.ReservationTime != "" || req.GuestCount != 0 {
+		before, err := GetReservationByID(ctx, req.RestaurantId, id)
+		if err != nil {
+			return &pb.Response{Message: "Reservation not found", Success: false}, err
+		}
+		after := *before
+		if req.TableId != "" {
+			after.TableId = req.TableId
+			update["tableid"] = req.TableId
+		}
+		if req.ReservationDate != "" {
+			after.ReservationDate = req.ReservationDate
+			update["reservationdate"] = req.ReservationDate
+		}
+		if req.ReservationTime != "" {
+			after.ReservationTime = req.ReservationTime
+			update["reservationtime"] = req.ReservationTime
+		}
+		if req.GuestCount != 0 {
+			after.GuestCount = int(req.GuestCount)
+			update["guestcount"] = int(req.GuestCount)
+		}
+		if response, err := checkReservationChange(ctx, *before, after); response != nil || err != nil {
+			return response, err
+		}
+	}
+	requests := m.Reservation{
+		Occasion:            req.Occasion,
+		DietaryRestrictions: normalizeDietaryRestrictions(req.DietaryRestrictions),
+		Notes:               req.Notes,
+		StaffNotes:          req.StaffNotes,
+	}
+	if err := validateSpecialRequests(requests); err != nil {
+		return nil, err
 	}
-	if req.ReservationDate != "" {
-		update["reservationdate"] = req.ReservationDate
+	if requests.Occasion != "" {
+		update["occasion"] = requests.Occasion
 	}
-	if req.GuestCount != 0 {
-		update["guestcount"] = req.GuestCount
+	if requests.DietaryRestrictions != nil {
+		update["dietaryrestrictions"] = requests.DietaryRestrictions
+	}
+	if requests.Notes != "" {
+		update["notes"] = requests.Notes
+	}
+	if requests.StaffNotes != "" {
+		update["staffnotes"] = requests.StaffNotes
 	}
 	if req.Status != "" {
 		update["status"] = req.Status
-		validStatuses := map[string]bool{
-			"confirmada": true,
-			"cancelada":  true,
-			"completada": true,
-		}
 		if !validStatuses[req.Status] {
-			return nil, fmt.Errorf("invalid status, expected one of: confirmada, cancelada, completada")
+			return nil, fmt.Errorf(invalidStatusMessage)
+		}
+		if req.Status == "sentada" {
+			update["seatedat"] = time.Now()
 		}
 	}
 	update["updateat"] = time.Now()
 
-	err := UpdateReservation(id, update)
+	err := UpdateReservation(ctx, req.RestaurantId, id, update, int(req.ExpectedVersion))
 	if err != nil {
 		return &pb.Response{Message: "Failed to update reservation", Success: false}, err
 	}
 	return &pb.Response{Message: "Reservation updated successfully", Success: true}, nil
 }
 
-func UpdateReservation(id string, update bson.M) error {
+// checkReservationChange valida un cambio de mesa, fecha, hora o tamaño de
+// grupo con las mismas reglas que al crear la reserva: horario y políticas
+// del restaurante, fiabilidad del cliente, capacidad de la mesa y franja
+// libre. Devuelve la respuesta de rechazo o nil si el cambio es válido.
+func checkReservationChange(ctx context.Context, before, after m.Reservation) (*pb.Response, error) {
+	ctx, span := tracing.Start(ctx, "controllers.checkReservationChange")
+	defer span.End()
+
+	moved := after.TableId != before.TableId || after.ReservationDate != before.ReservationDate || after.ReservationTime != before.ReservationTime
+	if !moved && after.GuestCount == before.GuestCount {
+		return nil, nil
+	}
+	if after.ReservationTime != before.ReservationTime {
+		parsed, err := time.Parse("15:04", after.ReservationTime)
+		if err != nil {
+			return &pb.Response{Message: "invalid time format, expected HH:MM", Success: false}, nil
+		}
+		if parsed.Minute() != 0 {
+			return &pb.Response{Message: "reservation time must be end in 00", Success: false}, nil
+		}
+	}
+
+	restaurant, err := GetRestaurantByID(ctx, before.RestaurantId)
+	if err != nil {
+		return &pb.Response{Message: "Restaurant not found", Success: false}, err
+	}
+	if err = ValidateRestaurantSlot(restaurant, after.ReservationDate, after.ReservationTime, after.GuestCount); err != nil {
+		return &pb.Response{Message: err.Error(), Success: false}, nil
+	}
+	if !anonymousWalkIn(before) {
+		_, rejection, err := applyReliabilityPolicy(ctx, restaurant, before.UserId, after.GuestCount, before.Status)
+		if err != nil {
+			return &pb.Response{Message: "Failed to check guest reliability", Success: false}, err
+		}
+		if rejection != "" {
+			return &pb.Response{Message: rejection, Success: false}, nil
+		}
+	}
+	table, err := GetRestaurantTable(ctx, before.RestaurantId, after.TableId)
+	if err != nil {
+		return &pb.Response{Message: "Table not found in restaurant", Success: false}, err
+	}
+	if after.GuestCount > table.Capacity {
+		return &pb.Response{Message: "The table is too small for this party size, please contact the restaurant", Success: false}, nil
+	}
+	if moved {
+		taken, err := slotTaken(ctx, after.TableId, after.ReservationDate, after.ReservationTime, "")
+		if err != nil {
+			return &pb.Response{Message: "Failed to check existing reservations", Success: false}, err
+		}
+		if taken {
+			return &pb.Response{Message: "The table is not available at the new date and time", Success: false}, nil
+		}
+	}
+	return nil, nil
+}
+
+// UpdateReservation aplica update si la reserva sigue en expectedVersion
+// (0 si el cliente no la indica) y devuelve ErrVersionConflict si no. La
+// escritura se condiciona a la versión leída, de modo que dos cambios
+// simultáneos no se pisan aunque el cliente no envíe versión.
+func UpdateReservation(ctx context.Context, restaurantID, id string, update bson.M, expectedVersion int) error {
+	ctx, span := tracing.Start(ctx, "controllers.UpdateReservation")
+	defer span.End()
+
+	if restaurantID == "" {
+		return fmt.Errorf("restaurantID is required")
+	}
 	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		log.Printf("invalid id format: %v", err)
+		slog.WarnContext(ctx, "invalid id format", "error", err)
 		return nil
 	}
 
 	collection := mongoClient.Database("reservations-db").Collection("reservations")
-	_, err = collection.UpdateOne(context.TODO(), bson.M{"_id": objectID}, bson.M{"$set": update})
+	filter := bson.M{"_id": objectID, "restaurantid": restaurantID, "deleted": notDeleted}
+	var before, reservation m.Reservation
+	err = collection.FindOne(ctx, filter).Decode(&before)
+	if err == mongo.ErrNoDocuments {
+		return err
+	}
 	if err != nil {
-		log.Printf("Failed to update reservation: %v", err)
+		slog.ErrorContext(ctx, "failed to find reservation", "error", err)
 		return err
 	}
-
-	if status, ok := update["status"].(string); ok && (status == "completada" || status == "cancelada") {
-		var reservation m.Reservation
-		err = collection.FindOne(context.TODO(), bson.M{"_id": objectID}).Decode(&reservation)
+	if expectedVersion != 0 && before.Version != expectedVersion {
+		return ErrVersionConflict
+	}
+	date, dateChanged := update["reservationdate"].(string)
+	slotTime, timeChanged := update["reservationtime"].(string)
+	if (dateChanged && date != before.ReservationDate) || (timeChanged && slotTime != before.ReservationTime) {
+		if err = checkGuestReschedule(ctx, before); err != nil {
+			return err
+		}
+	}
+	if update["status"] == "cancelada" && awaitingGuest(before.Status) {
+		outcome, err := evaluateCancellation(ctx, before)
 		if err != nil {
-			log.Printf("Failed to find updated reservation: %v", err)
 			return err
 		}
+		update["cancellation"] = outcome
+	} else if update["status"] == "cancelada" && before.Status != StatusPendingPayment && before.Status != "cancelada" {
+		// Una reserva sentada, completada o no presentada ya no la cancela
+		// el cliente
+		if identity, ok := auth.FromContext(ctx); ok && !identity.IsStaff() {
+			return ErrNotCancellable
+		}
+	}
+
+	deposit, err := reviewGuestDeposit(ctx, before, update)
+	if err != nil {
+		return err
+	}
 
-		err = UpdateTableIsReserved(reservation.TableId, false)
+	reservation, err = updateReservationVersioned(ctx, filter, before, update, reservationEvents)
+	if err != nil {
+		if deposit != nil {
+			settleDeposit(ctx, m.Reservation{ID: id, Deposit: deposit})
+		}
+		return err
+	}
+	recordAudit(ctx, AuditEntityReservation, id, restaurantID, AuditUpdate, before, reservation)
+	// Al sentarse se programa el cierre automático y al pedir un depósito,
+	// su plazo de pago
+	statusJobs := reservation.Status != before.Status && (reservation.Status == "sentada" || reservation.Status == StatusPendingPayment)
+	if statusJobs || reservation.ReservationDate != before.ReservationDate || reservation.ReservationTime != before.ReservationTime {
+		scheduleReservationJobs(ctx, reservation)
+	}
+
+	status, ok := update["status"].(string)
+	if ok && status != before.Status {
+		metrics.ReservationStatusChanged(status)
+		// Las cancelaciones del personal no cuentan en la fiabilidad del
+		// cliente, igual que sus borrados
+		if status != "cancelada" || guestCancelling(ctx, before) {
+			recordReliability(ctx, reservation, status)
+		}
+		if status == "completada" {
+			recordGuestVisit(ctx, reservation)
+		}
+	}
+
+	if ok && (status == "completada" || status == "cancelada" || status == StatusNoShow) {
+		err = UpdateTableIsReserved(ctx, reservation.TableId, false)
 		if err != nil {
-			log.Printf("Failed to update table status: %v", err)
+			slog.ErrorContext(ctx, "failed to update table status", "error", err)
 			return err
 		}
+
+		if status == "cancelada" {
+			settleDeposit(ctx, reservation)
+			OfferFreedSlot(ctx, reservation.TableId, reservation.ReservationDate, reservation.ReservationTime)
+		}
 	}
 
 	return nil
 }
 
 // DELETE
-func DeleteReservationHandler(req *pb.DeleteReservationRequest) (*pb.Response, error) {
+func DeleteReservationHandler(ctx context.Context, req *pb.DeleteReservationRequest) (*pb.Response, error) {
+	ctx, span := tracing.Start(ctx, "controllers.DeleteReservationHandler")
+	defer span.End()
+
 	id := req.Id
-	err := DeleteReservation(id)
+	err := DeleteReservation(ctx, req.RestaurantId, id)
 	if err != nil {
 		return &pb.Response{Message: "Failed to delete reservation", Success: false}, err
 	}
 	return &pb.Response{Message: "Reservation deleted successfully", Success: true}, nil
 }
 
-func DeleteReservation(id string) error {
+func DeleteReservation(ctx context.Context, restaurantID, id string) error {
+	ctx, span := tracing.Start(ctx, "controllers.DeleteReservation")
+	defer span.End()
+
+	if restaurantID == "" {
+		return fmt.Errorf("restaurantID is required")
+	}
 	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		log.Printf("invalid id format: %v", err)
+		slog.WarnContext(ctx, "invalid id format", "error", err)
+		return nil
+	}
+
+	// El borrado es lógico: la reserva se conserva para informes y
+	// reclamaciones hasta que la purga la elimine tras la retención.
+	collection := mongoClient.Database("reservations-db").Collection("reservations")
+	filter := bson.M{"_id": objectID, "restaurantid": restaurantID, "deleted": notDeleted}
+	update := bson.M{"deleted": true, "deletedat": time.Now(), "deletedby": actorFromContext(ctx)}
+	var reservation m.Reservation
+	if err = collection.FindOne(ctx, filter).Decode(&reservation); err != nil {
+		slog.ErrorContext(ctx, "failed to find reservation", "error", err)
+		return err
+	}
+	// Solo cuenta como cancelación si la borra el propio cliente; los
+	// borrados del personal son correcciones.
+	guestCancellation := awaitingGuest(reservation.Status) && guestCancelling(ctx, reservation)
+	if guestCancellation {
+		outcome, err := evaluateCancellation(ctx, reservation)
+		if err != nil {
+			return err
+		}
+		update["cancellation"] = outcome
+	}
+	// Para los demás servicios, borrar una reserva pendiente es cancelarla
+	deleted, err := updateReservationVersioned(ctx, filter, reservation, update, func([]m.FieldChange) []string {
+		if awaitingGuest(reservation.Status) {
+			return []string{events.ReservationCancelled}
+		}
 		return nil
+	})
+	if err != nil {
+		return err
+	}
+	recordAudit(ctx, AuditEntityReservation, id, restaurantID, AuditDelete, reservation, deleted)
+	if guestCancellation {
+		recordReliability(ctx, deleted, "cancelada")
+	}
+
+	if awaitingGuest(reservation.Status) || reservation.Status == StatusPendingPayment {
+		settleDeposit(ctx, deleted)
+	}
+	if awaitingGuest(reservation.Status) || reservation.Status == StatusPendingPayment || reservation.Status == "sentada" {
+		if err = UpdateTableIsReserved(ctx, reservation.TableId, false); err != nil {
+			return err
+		}
+	}
+	if awaitingGuest(reservation.Status) {
+		OfferFreedSlot(ctx, reservation.TableId, reservation.ReservationDate, reservation.ReservationTime)
+	}
+	return nil
+}
+
+// RESTORE
+func RestoreReservationHandler(ctx context.Context, req *pb.RestoreReservationRequest) (*pb.Response, error) {
+	ctx, span := tracing.Start(ctx, "controllers.RestoreReservationHandler")
+	defer span.End()
+
+	err := RestoreReservation(ctx, req.RestaurantId, req.Id)
+	if err != nil {
+		return &pb.Response{Message: "Failed to restore reservation", Success: false}, err
+	}
+	return &pb.Response{Message: "Reservation restored successfully", Success: true}, nil
+}
+
+// RestoreReservation deshace el borrado de una reserva dentro del periodo
+// de retención, siempre que su franja siga libre.
+func RestoreReservation(ctx context.Context, restaurantID, id string) error {
+	ctx, span := tracing.Start(ctx, "controllers.RestoreReservation")
+	defer span.End()
+
+	if restaurantID == "" {
+		return fmt.Errorf("restaurantID is required")
+	}
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		slog.WarnContext(ctx, "invalid id format", "error", err)
+		return err
 	}
 
 	collection := mongoClient.Database("reservations-db").Collection("reservations")
-	_, err = collection.DeleteOne(context.TODO(), bson.M{"_id": objectID})
+	filter := bson.M{"_id": objectID, "restaurantid": restaurantID, "deleted": true}
+	var reservation m.Reservation
+	err = collection.FindOne(ctx, filter).Decode(&reservation)
+	if err == mongo.ErrNoDocuments {
+		return fmt.Errorf("deleted reservation not found")
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to find deleted reservation", "error", err)
+		return err
+	}
+	if time.Since(reservation.DeletedAt) > reservationRetention() {
+		return fmt.Errorf("retention window expired, the reservation can no longer be restored")
+	}
+
+	active := awaitingGuest(reservation.Status) || reservation.Status == "sentada"
+	if active {
+		taken, err := slotTaken(ctx, reservation.TableId, reservation.ReservationDate, reservation.ReservationTime, "")
+		if err != nil {
+			return err
+		}
+		if taken {
+			return fmt.Errorf("the reservation slot is no longer available")
+		}
+	}
+
+	update := bson.M{"deleted": false, "deletedat": time.Time{}, "deletedby": "", "cancellation": nil, "updateat": time.Now()}
+	restored, err := updateReservationVersioned(ctx, filter, reservation, update, func([]m.FieldChange) []string {
+		return []string{events.ReservationUpdated}
+	})
 	if err != nil {
-		log.Printf("Failed to delete reservation: %v", err)
 		return err
 	}
+	recordAudit(ctx, AuditEntityReservation, id, restaurantID, AuditRestore, reservation, restored)
+	scheduleReservationJobs(ctx, restored)
+
+	if active {
+		return UpdateTableIsReserved(ctx, reservation.TableId, true)
+	}
 	return nil
 }
 
-func ReservationExists(tableId, reservationDate, reservationTime string) (bool, error) {
+// PurgeDeletedReservations elimina definitivamente las reservas borradas
+// hace más que el periodo de retención. Cada eliminación queda auditada con
+// el último estado de la reserva.
+func PurgeDeletedReservations(ctx context.Context) {
+	ctx, span := tracing.Start(ctx, "controllers.PurgeDeletedReservations")
+	defer span.End()
+
+	collection := mongoClient.Database("reservations-db").Collection("reservations")
+	cursor, err := collection.Find(ctx, bson.M{
+		"deleted":   true,
+		"deletedat": bson.M{"$lt": time.Now().Add(-reservationRetention())},
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to find purgeable reservations", "error", err)
+		return
+	}
+	var reservations []m.Reservation
+	if err = cursor.All(ctx, &reservations); err != nil {
+		slog.ErrorContext(ctx, "failed to decode purgeable reservations", "error", err)
+		return
+	}
+
+	for _, reservation := range reservations {
+		objectID, err := primitive.ObjectIDFromHex(reservation.ID)
+		if err != nil {
+			continue
+		}
+		result, err := collection.DeleteOne(ctx, bson.M{"_id": objectID, "deleted": true})
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to purge reservation", "reservation_id", reservation.ID, "error", err)
+			continue
+		}
+		if result.DeletedCount > 0 {
+			recordAudit(ctx, AuditEntityReservation, reservation.ID, reservation.RestaurantId, AuditPurge, reservation, nil)
+		}
+	}
+	if len(reservations) > 0 {
+		slog.InfoContext(ctx, "purged deleted reservations", "count", len(reservations))
+	}
+}
+
+// StartReservationPurger ejecuta periódicamente la purga de reservas
+// borradas hasta que se cancele ctx.
+func StartReservationPurger(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				PurgeDeletedReservations(ctx)
+			}
+		}
+	}()
+}
+
+// ReservationExists indica si la mesa está ocupada en esa franja, ya sea por
+// una reserva activa o por un bloqueo temporal vigente.
+func ReservationExists(ctx context.Context, tableId, reservationDate, reservationTime string) (bool, error) {
+	ctx, span := tracing.Start(ctx, "controllers.ReservationExists")
+	defer span.End()
+
+	return slotTaken(ctx, tableId, reservationDate, reservationTime, "")
+}
+
+func slotTaken(ctx context.Context, tableId, reservationDate, reservationTime, holdToken string) (bool, error) {
+	ctx, span := tracing.Start(ctx, "controllers.slotTaken")
+	defer span.End()
+
 	collection := mongoClient.Database("reservations-db").Collection("reservations")
 
 	// Convertir la hora de la reserva a un objeto time.Time
@@ -317,16 +919,26 @@ func ReservationExists(tableId, reservationDate, reservationTime string) (bool,
 		return false, fmt.Errorf("invalid time format, expected HH:MM")
 	}
 
-	// Buscar reservas que se superpongan con la nueva reserva
+	// Buscar reservas que se superpongan con la nueva reserva, empiecen en
+	// la franja o la ocupen durante su turno (las canceladas ya no ocupan la
+	// mesa)
 	filter := bson.M{
 		"tableid":         tableId,
 		"reservationdate": reservationDate,
-		"reservationtime": reservationTime,
-	}
-	count, err := collection.CountDocuments(context.TODO(), filter)
+		"$or": bson.A{
+			bson.M{"reservationtime": reservationTime},
+			bson.M{"blockedslots": reservationTime},
+		},
+		"status":  bson.M{"$ne": "cancelada"},
+		"deleted": notDeleted,
+	}
+	count, err := collection.CountDocuments(ctx, filter)
 	if err != nil {
-		log.Printf("failed to check existing reservations: %v", err)
+		slog.ErrorContext(ctx, "failed to check existing reservations", "error", err)
 		return false, err
 	}
-	return count > 0, nil
+	if count > 0 {
+		return true, nil
+	}
+	return SlotHeld(ctx, tableId, reservationDate, reservationTime, holdToken)
 }
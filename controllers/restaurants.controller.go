@@ -0,0 +1,349 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	m "ms-reservas/models"
+	pb "ms-reservas/protos_pb/proto"
+	"ms-reservas/tracing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CREATE
+func CreateRestaurantHandler(ctx context.Context, req *pb.CreateRestaurantRequest) (*pb.Response, error) {
+	ctx, span := tracing.Start(ctx, "controllers.CreateRestaurantHandler")
+	defer span.End()
+
+	restaurant := m.Restaurant{
+		Name:     req.Name,
+		Address:  req.Address,
+		Timezone: req.Timezone,
+		Schedule: fromPbSchedule(req.Schedule),
+		Policies: fromPbPolicies(req.Policies),
+		CreateAt: time.Now(),
+	}
+	if restaurant.Name == "" {
+		return &pb.Response{Message: "name is required", Success: false}, nil
+	}
+	if restaurant.Timezone == "" {
+		restaurant.Timezone = "UTC"
+	}
+	if err := validateRestaurant(restaurant); err != nil {
+		return &pb.Response{Message: err.Error(), Success: false}, nil
+	}
+
+	id, err := CreateRestaurant(ctx, restaurant)
+	if err != nil {
+		return &pb.Response{Message: "failed to create restaurant", Success: false}, err
+	}
+	return &pb.Response{Message: fmt.Sprintf("restaurant %s created successfully", id), Success: true}, nil
+}
+
+func CreateRestaurant(ctx context.Context, restaurant m.Restaurant) (string, error) {
+	ctx, span := tracing.Start(ctx, "controllers.CreateRestaurant")
+	defer span.End()
+
+	collection := mongoClient.Database("reservations-db").Collection("restaurants")
+	result, err := collection.InsertOne(ctx, restaurant)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to insert restaurant", "error", err)
+		return "", err
+	}
+	return result.InsertedID.(primitive.ObjectID).Hex(), nil
+}
+
+// GET ALL
+func GetRestaurantsHandler(ctx context.Context, req *pb.Empty) (*pb.Restaurants, error) {
+	ctx, span := tracing.Start(ctx, "controllers.GetRestaurantsHandler")
+	defer span.End()
+
+	restaurants, err := GetRestaurants(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var pbRestaurants []*pb.Restaurant
+	for _, restaurant := range restaurants {
+		pbRestaurants = append(pbRestaurants, toPbRestaurant(restaurant))
+	}
+	return &pb.Restaurants{Restaurants: pbRestaurants}, nil
+}
+
+func GetRestaurants(ctx context.Context) ([]m.Restaurant, error) {
+	ctx, span := tracing.Start(ctx, "controllers.GetRestaurants")
+	defer span.End()
+
+	collection := mongoClient.Database("reservations-db").Collection("restaurants")
+	cursor, err := collection.Find(ctx, bson.M{})
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to find restaurants", "error", err)
+		return nil, err
+	}
+	var restaurants []m.Restaurant
+	if err = cursor.All(ctx, &restaurants); err != nil {
+		slog.ErrorContext(ctx, "failed to decode restaurants", "error", err)
+		return nil, err
+	}
+	return restaurants, nil
+}
+
+// GET BY ID
+func GetRestaurantByIDHandler(ctx context.Context, req *pb.GetRestaurantByIDRequest) (*pb.Restaurant, error) {
+	ctx, span := tracing.Start(ctx, "controllers.GetRestaurantByIDHandler")
+	defer span.End()
+
+	restaurant, err := GetRestaurantByID(ctx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+	return toPbRestaurant(*restaurant), nil
+}
+
+func GetRestaurantByID(ctx context.Context, id string) (*m.Restaurant, error) {
+	ctx, span := tracing.Start(ctx, "controllers.GetRestaurantByID")
+	defer span.End()
+
+	if id == "" {
+		return nil, fmt.Errorf("restaurantID is required")
+	}
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		slog.WarnContext(ctx, "invalid id format", "error", err)
+		return nil, err
+	}
+
+	collection := mongoClient.Database("reservations-db").Collection("restaurants")
+	var restaurant m.Restaurant
+	err = collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&restaurant)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to find restaurant", "error", err)
+		return nil, err
+	}
+	return &restaurant, nil
+}
+
+// RestaurantName devuelve el nombre del restaurante, para las plantillas de
+// notificaciones.
+func RestaurantName(ctx context.Context, id string) (string, error) {
+	restaurant, err := GetRestaurantByID(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	return restaurant.Name, nil
+}
+
+// UPDATE
+func UpdateRestaurantHandler(ctx context.Context, req *pb.UpdateRestaurantRequest) (*pb.Response, error) {
+	ctx, span := tracing.Start(ctx, "controllers.UpdateRestaurantHandler")
+	defer span.End()
+
+	restaurant, err := GetRestaurantByID(ctx, req.Id)
+	if err != nil {
+		return &pb.Response{Message: "restaurant not found", Success: false}, err
+	}
+
+	update := bson.M{}
+	if req.Name != "" {
+		restaurant.Name = req.Name
+		update["name"] = req.Name
+	}
+	if req.Address != "" {
+		restaurant.Address = req.Address
+		update["address"] = req.Address
+	}
+	if req.Timezone != "" {
+		restaurant.Timezone = req.Timezone
+		update["timezone"] = req.Timezone
+	}
+	if req.Schedule != nil {
+		restaurant.Schedule = fromPbSchedule(req.Schedule)
+		update["schedule"] = restaurant.Schedule
+	}
+	if req.Policies != nil {
+		restaurant.Policies = fromPbPolicies(req.Policies)
+		update["policies"] = restaurant.Policies
+	}
+	if err := validateRestaurant(*restaurant); err != nil {
+		return &pb.Response{Message: err.Error(), Success: false}, nil
+	}
+	update["updateat"] = time.Now()
+
+	err = UpdateRestaurant(ctx, req.Id, update)
+	if err != nil {
+		return &pb.Response{Message: "failed to update restaurant", Success: false}, err
+	}
+	return &pb.Response{Message: "restaurant updated successfully", Success: true}, nil
+}
+
+func UpdateRestaurant(ctx context.Context, id string, update bson.M) error {
+	ctx, span := tracing.Start(ctx, "controllers.UpdateRestaurant")
+	defer span.End()
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		slog.WarnContext(ctx, "invalid id format", "error", err)
+		return err
+	}
+
+	collection := mongoClient.Database("reservations-db").Collection("restaurants")
+	_, err = collection.UpdateOne(ctx, bson.M{"_id": objectID}, bson.M{"$set": update})
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to update restaurant", "error", err)
+		return err
+	}
+	return nil
+}
+
+func validateRestaurant(restaurant m.Restaurant) error {
+	if _, err := time.LoadLocation(restaurant.Timezone); err != nil {
+		return fmt.Errorf("invalid timezone %q", restaurant.Timezone)
+	}
+	const timeFormat = "15:04"
+	for _, hours := range restaurant.Schedule {
+		if hours.Weekday < 0 || hours.Weekday > 6 {
+			return fmt.Errorf("invalid weekday %d, expected 0 (sunday) to 6 (saturday)", hours.Weekday)
+		}
+		open, err := time.Parse(timeFormat, hours.Open)
+		if err != nil || open.Format(timeFormat) != hours.Open {
+			return fmt.Errorf("invalid opening time format, expected HH:MM")
+		}
+		closing, err := time.Parse(timeFormat, hours.Close)
+		if err != nil || closing.Format(timeFormat) != hours.Close {
+			return fmt.Errorf("invalid closing time format, expected HH:MM")
+		}
+		if !open.Before(closing) {
+			return fmt.Errorf("opening time must be before closing time")
+		}
+	}
+	if restaurant.Policies.MaxGuestCount < 0 || restaurant.Policies.TurnTimeMinutes < 0 || restaurant.Policies.MaxAdvanceDays < 0 {
+		return fmt.Errorf("policies cannot be negative")
+	}
+	policies := restaurant.Policies
+	for _, threshold := range []int{policies.ReliabilityConfirmBelow, policies.ReliabilityMaxGuestsBelow, policies.ReliabilityBlockBelow} {
+		if threshold < 0 || threshold > 100 {
+			return fmt.Errorf("reliability thresholds must be between 0 and 100")
+		}
+	}
+	if policies.ReliabilityMaxGuestsBelow > 0 && policies.ReliabilityMaxGuests <= 0 {
+		return fmt.Errorf("reliabilityMaxGuests is required when reliabilityMaxGuestsBelow is set")
+	}
+	if err := validateCancellationPolicy(policies.Cancellation); err != nil {
+		return err
+	}
+	if err := validateDepositPolicy(policies.Deposit); err != nil {
+		return err
+	}
+	return nil
+}
+
+// restaurantLocation devuelve la zona horaria del restaurante; las fechas y
+// horas de sus reservas se interpretan siempre en ella.
+func restaurantLocation(restaurant *m.Restaurant) *time.Location {
+	loc, err := time.LoadLocation(restaurant.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// restaurantTurnTime usa la política del restaurante o, si no la define,
+// TURN_TIME_MINUTES.
+func restaurantTurnTime(restaurant *m.Restaurant) time.Duration {
+	if restaurant.Policies.TurnTimeMinutes > 0 {
+		return time.Duration(restaurant.Policies.TurnTimeMinutes) * time.Minute
+	}
+	return turnTime()
+}
+
+// ValidateRestaurantSlot comprueba que la franja cae dentro del horario del
+// restaurante y respeta sus políticas de tamaño de grupo y antelación.
+func ValidateRestaurantSlot(restaurant *m.Restaurant, reservationDate, reservationTime string, guestCount int) error {
+	loc := restaurantLocation(restaurant)
+	slot, err := time.ParseInLocation("02-01-2006 15:04", reservationDate+" "+reservationTime, loc)
+	if err != nil {
+		return fmt.Errorf("invalid date or time format, expected dd-mm-yyyy and HH:MM")
+	}
+
+	if limit := restaurant.Policies.MaxGuestCount; limit > 0 && guestCount > limit {
+		return fmt.Errorf("guestCount exceeds the restaurant limit of %d", limit)
+	}
+	if days := restaurant.Policies.MaxAdvanceDays; days > 0 && slot.After(time.Now().In(loc).AddDate(0, 0, days)) {
+		return fmt.Errorf("reservations can only be made up to %d days in advance", days)
+	}
+
+	if len(restaurant.Schedule) == 0 {
+		return nil
+	}
+	for _, hours := range restaurant.Schedule {
+		if hours.Weekday == int(slot.Weekday()) && reservationTime >= hours.Open && reservationTime < hours.Close {
+			return nil
+		}
+	}
+	return fmt.Errorf("restaurant is closed at %s %s", reservationDate, reservationTime)
+}
+
+func toPbRestaurant(restaurant m.Restaurant) *pb.Restaurant {
+	var schedule []*pb.OpeningHours
+	for _, hours := range restaurant.Schedule {
+		schedule = append(schedule, &pb.OpeningHours{
+			Weekday: int32(hours.Weekday),
+			Open:    hours.Open,
+			Close:   hours.Close,
+		})
+	}
+	return &pb.Restaurant{
+		Id:       restaurant.ID,
+		Name:     restaurant.Name,
+		Address:  restaurant.Address,
+		Timezone: restaurant.Timezone,
+		Schedule: schedule,
+		Policies: &pb.RestaurantPolicies{
+			MaxGuestCount:   int32(restaurant.Policies.MaxGuestCount),
+			TurnTimeMinutes: int32(restaurant.Policies.TurnTimeMinutes),
+			MaxAdvanceDays:  int32(restaurant.Policies.MaxAdvanceDays),
+
+			ReliabilityConfirmBelow:   int32(restaurant.Policies.ReliabilityConfirmBelow),
+			ReliabilityMaxGuestsBelow: int32(restaurant.Policies.ReliabilityMaxGuestsBelow),
+			ReliabilityMaxGuests:      int32(restaurant.Policies.ReliabilityMaxGuests),
+			ReliabilityBlockBelow:     int32(restaurant.Policies.ReliabilityBlockBelow),
+			Cancellation:              toPbCancellationPolicy(restaurant.Policies.Cancellation),
+			Deposit:                   toPbDepositPolicy(restaurant.Policies.Deposit),
+		},
+		CreateAt: restaurant.CreateAt.Format(time.RFC3339),
+		UpdateAt: restaurant.UpdateAt.Format(time.RFC3339),
+	}
+}
+
+func fromPbSchedule(schedule []*pb.OpeningHours) []m.OpeningHours {
+	var hours []m.OpeningHours
+	for _, h := range schedule {
+		hours = append(hours, m.OpeningHours{
+			Weekday: int(h.Weekday),
+			Open:    h.Open,
+			Close:   h.Close,
+		})
+	}
+	return hours
+}
+
+func fromPbPolicies(policies *pb.RestaurantPolicies) m.RestaurantPolicies {
+	if policies == nil {
+		return m.RestaurantPolicies{}
+	}
+	return m.RestaurantPolicies{
+		MaxGuestCount:   int(policies.MaxGuestCount),
+		TurnTimeMinutes: int(policies.TurnTimeMinutes),
+		MaxAdvanceDays:  int(policies.MaxAdvanceDays),
+
+		ReliabilityConfirmBelow:   int(policies.ReliabilityConfirmBelow),
+		ReliabilityMaxGuestsBelow: int(policies.ReliabilityMaxGuestsBelow),
+		ReliabilityMaxGuests:      int(policies.ReliabilityMaxGuests),
+		ReliabilityBlockBelow:     int(policies.ReliabilityBlockBelow),
+		Cancellation:              fromPbCancellationPolicy(policies.Cancellation),
+		Deposit:                   fromPbDepositPolicy(policies.Deposit),
+	}
+}
@@ -0,0 +1,336 @@
+package controllers
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"ms-reservas/auth"
+	m "ms-reservas/models"
+	pb "ms-reservas/protos_pb/proto"
+	"ms-reservas/tracing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Los códigos de confirmación no usan 0/O ni 1/I para que se puedan dictar
+// por teléfono. 32 símbolos en 8 posiciones dan 2^40 combinaciones.
+const (
+	confirmationCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	confirmationCodeLength   = 8
+)
+
+// ErrReservationCodeNotFound indica que no hay reserva con el código o que
+// el apellido o el teléfono no coinciden; no se distingue entre ambos casos
+// para no confirmar qué códigos existen.
+var ErrReservationCodeNotFound = errors.New("reservation not found")
+
+// ErrTooManyCodeAttempts indica que el código está bloqueado por demasiados
+// intentos fallidos recientes.
+var ErrTooManyCodeAttempts = errors.New("too many failed attempts for this confirmation code, try again later")
+
+// Cada código admite maxCodeAttempts intentos fallidos por ventana. Se suma
+// al límite por IP del servidor para frenar a quien prueba apellidos o
+// teléfonos contra un mismo código desde muchas direcciones.
+const (
+	maxCodeAttempts    = 5
+	codeAttemptsWindow = 15 * time.Minute
+)
+
+// ErrNotModifiable indica que la reserva ya no se puede cambiar desde el
+// autoservicio (está sentada, completada, cancelada...).
+var ErrNotModifiable = errors.New("reservation can no longer be changed online, please contact the restaurant")
+
+// EnsureReservationIndexes crea el índice único de códigos de confirmación
+// y los de los intentos fallidos por código, que caducan solos al acabar su
+// ventana. Las reservas anteriores a los códigos no tienen el campo.
+func EnsureReservationIndexes(ctx context.Context) error {
+	collection := mongoClient.Database("reservations-db").Collection("reservations")
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "confirmationcode", Value: 1}},
+		Options: options.Index().SetUnique(true).
+			SetPartialFilterExpression(bson.M{"confirmationcode": bson.M{"$exists": true}}),
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to create reservation indexes", "error", err)
+		return err
+	}
+	attempts := mongoClient.Database("reservations-db").Collection("code_attempts")
+	_, err = attempts.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "code", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "expiresat", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to create code attempt indexes", "error", err)
+	}
+	return err
+}
+
+// newConfirmationCode genera un código aleatorio. La unicidad la garantiza
+// el índice; una colisión solo hace fallar la creación de la reserva.
+func newConfirmationCode() (string, error) {
+	buf := make([]byte, confirmationCodeLength)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	for i, b := range buf {
+		buf[i] = confirmationCodeAlphabet[int(b)%len(confirmationCodeAlphabet)]
+	}
+	return string(buf), nil
+}
+
+// normalizeConfirmationCode acepta el código en minúsculas y con guiones o
+// espacios.
+func normalizeConfirmationCode(code string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, strings.ToUpper(code))
+}
+
+// guestMatches comprueba el apellido contra el nombre de la reserva (sin el
+// nombre de pila, admitiendo apellidos compuestos) o el teléfono, ignorando
+// prefijos internacionales.
+func guestMatches(reservation m.Reservation, surname, phone string) bool {
+	if surname = strings.TrimSpace(surname); surname != "" {
+		words := strings.Fields(strings.ToLower(reservation.GuestName))
+		if len(words) > 1 {
+			words = words[1:]
+		}
+		wanted := strings.Fields(strings.ToLower(surname))
+		for i := 0; i+len(wanted) <= len(words); i++ {
+			if slices.Equal(words[i:i+len(wanted)], wanted) {
+				return true
+			}
+		}
+	}
+	given, stored := digits(phone), digits(reservation.GuestPhone)
+	if len(given) >= 6 && len(stored) >= 6 {
+		return strings.HasSuffix(given, stored) || strings.HasSuffix(stored, given)
+	}
+	return false
+}
+
+func digits(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, s)
+}
+
+// FindReservationByCode devuelve la reserva del código si el apellido o el
+// teléfono coinciden con los de la reserva. Tras maxCodeAttempts fallos en
+// la ventana, el código queda bloqueado hasta que esta termina.
+func FindReservationByCode(ctx context.Context, code, surname, phone string) (*m.Reservation, error) {
+	ctx, span := tracing.Start(ctx, "controllers.FindReservationByCode")
+	defer span.End()
+
+	code = normalizeConfirmationCode(code)
+	if code == "" {
+		return nil, fmt.Errorf("confirmationCode is required")
+	}
+	if strings.TrimSpace(surname) == "" && phone == "" {
+		return nil, fmt.Errorf("surname or phone is required")
+	}
+	locked, err := codeLocked(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if locked {
+		return nil, ErrTooManyCodeAttempts
+	}
+
+	collection := mongoClient.Database("reservations-db").Collection("reservations")
+	var reservation m.Reservation
+	err = collection.FindOne(ctx, bson.M{"confirmationcode": code, "deleted": notDeleted}).Decode(&reservation)
+	if err != nil && err != mongo.ErrNoDocuments {
+		slog.ErrorContext(ctx, "failed to find reservation", "error", err)
+		return nil, err
+	}
+	// Los códigos inexistentes también cuentan, para que el bloqueo no
+	// revele qué códigos existen
+	if err == mongo.ErrNoDocuments || !guestMatches(reservation, surname, phone) {
+		recordCodeFailure(ctx, code)
+		return nil, ErrReservationCodeNotFound
+	}
+	return &reservation, nil
+}
+
+// codeLocked indica si el código ha agotado sus intentos en la ventana
+// actual.
+func codeLocked(ctx context.Context, code string) (bool, error) {
+	collection := mongoClient.Database("reservations-db").Collection("code_attempts")
+	var attempts struct {
+		Failures int `bson:"failures"`
+	}
+	err := collection.FindOne(ctx, bson.M{"code": code, "expiresat": bson.M{"$gt": time.Now()}}).Decode(&attempts)
+	if err == mongo.ErrNoDocuments {
+		return false, nil
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to find code attempts", "error", err)
+		return false, err
+	}
+	return attempts.Failures >= maxCodeAttempts, nil
+}
+
+// recordCodeFailure suma un fallo al código. El primer fallo abre una
+// ventana nueva; los siguientes se suman a ella hasta que caduca. Un fallo
+// al guardarlo se registra pero no cambia la respuesta.
+func recordCodeFailure(ctx context.Context, code string) {
+	collection := mongoClient.Database("reservations-db").Collection("code_attempts")
+	now := time.Now()
+	result, err := collection.UpdateOne(ctx,
+		bson.M{"code": code, "expiresat": bson.M{"$gt": now}},
+		bson.M{"$inc": bson.M{"failures": 1}},
+	)
+	if err == nil && result.MatchedCount == 0 {
+		_, err = collection.UpdateOne(ctx,
+			bson.M{"code": code},
+			bson.M{"$set": bson.M{"failures": 1, "expiresat": now.Add(codeAttemptsWindow)}},
+			options.Update().SetUpsert(true),
+		)
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to record code attempt", "error", err)
+	}
+}
+
+// selfServiceContext hace que los cambios por código se apliquen en nombre
+// del cliente de la reserva: quedan a su nombre en el historial y se les
+// aplican las mismas políticas que a un huésped autenticado.
+func selfServiceContext(ctx context.Context, reservation m.Reservation) (context.Context, error) {
+	if !awaitingGuest(reservation.Status) && reservation.Status != StatusPendingPayment {
+		return nil, ErrNotModifiable
+	}
+	return auth.NewContext(ctx, &auth.Identity{Subject: reservation.UserId, Roles: []string{auth.RoleGuest}}), nil
+}
+
+// GET BY CODE
+func GetReservationByCodeHandler(ctx context.Context, req *pb.ReservationCodeRequest) (*pb.Reservation, error) {
+	ctx, span := tracing.Start(ctx, "controllers.GetReservationByCodeHandler")
+	defer span.End()
+
+	reservation, err := FindReservationByCode(ctx, req.ConfirmationCode, req.Surname, req.Phone)
+	if err != nil {
+		return nil, err
+	}
+	return toPbSelfServiceReservation(*reservation), nil
+}
+
+// toPbSelfServiceReservation es la vista de la reserva para quien solo
+// tiene el código: lo necesario para revisarla, cambiarla o pagar el
+// depósito, sin identificadores internos ni los datos de contacto, que
+// quien adivine un apellido no debe poder leer.
+func toPbSelfServiceReservation(reservation m.Reservation) *pb.Reservation {
+	return &pb.Reservation{
+		RestaurantId:        reservation.RestaurantId,
+		ReservationDate:     reservation.ReservationDate,
+		ReservationTime:     reservation.ReservationTime,
+		GuestCount:          int32(reservation.GuestCount),
+		Status:              reservation.Status,
+		ConfirmationCode:    reservation.ConfirmationCode,
+		Version:             int32(reservation.Version),
+		GuestName:           reservation.GuestName,
+		Language:            reservation.Language,
+		Cancellation:        toPbCancellationOutcome(reservation.Cancellation),
+		Deposit:             toPbDeposit(reservation.Deposit),
+		Occasion:            reservation.Occasion,
+		DietaryRestrictions: reservation.DietaryRestrictions,
+		Notes:               reservation.Notes,
+	}
+}
+
+// UPDATE BY CODE
+func UpdateReservationByCodeHandler(ctx context.Context, req *pb.UpdateReservationByCodeRequest) (*pb.Response, error) {
+	ctx, span := tracing.Start(ctx, "controllers.UpdateReservationByCodeHandler")
+	defer span.End()
+
+	reservation, err := FindReservationByCode(ctx, req.ConfirmationCode, req.Surname, req.Phone)
+	if err != nil {
+		return nil, err
+	}
+	ctx, err = selfServiceContext(ctx, *reservation)
+	if err != nil {
+		return nil, err
+	}
+
+	update := bson.M{}
+	after := *reservation
+	if req.ReservationDate != "" && req.ReservationDate != after.ReservationDate {
+		after.ReservationDate = req.ReservationDate
+		update["reservationdate"] = after.ReservationDate
+	}
+	if req.ReservationTime != "" && req.ReservationTime != after.ReservationTime {
+		after.ReservationTime = req.ReservationTime
+		update["reservationtime"] = after.ReservationTime
+	}
+	if req.GuestCount != 0 && int(req.GuestCount) != after.GuestCount {
+		after.GuestCount = int(req.GuestCount)
+		update["guestcount"] = after.GuestCount
+	}
+	if response, err := checkReservationChange(ctx, *reservation, after); response != nil || err != nil {
+		return response, err
+	}
+
+	requests := m.Reservation{
+		Occasion:            req.Occasion,
+		DietaryRestrictions: normalizeDietaryRestrictions(req.DietaryRestrictions),
+		Notes:               req.Notes,
+	}
+	if err = validateSpecialRequests(requests); err != nil {
+		return &pb.Response{Message: err.Error(), Success: false}, nil
+	}
+	if requests.Occasion != "" {
+		update["occasion"] = requests.Occasion
+	}
+	if requests.DietaryRestrictions != nil {
+		update["dietaryrestrictions"] = requests.DietaryRestrictions
+	}
+	if requests.Notes != "" {
+		update["notes"] = requests.Notes
+	}
+	if len(update) == 0 {
+		return &pb.Response{Message: "Nothing to update", Success: false}, nil
+	}
+	update["updateat"] = time.Now()
+
+	err = UpdateReservation(ctx, reservation.RestaurantId, reservation.ID, update, int(req.ExpectedVersion))
+	if err != nil {
+		return &pb.Response{Message: "Failed to update reservation", Success: false}, err
+	}
+	return &pb.Response{Message: "Reservation updated successfully", Success: true, ConfirmationCode: reservation.ConfirmationCode}, nil
+}
+
+// CANCEL BY CODE
+func CancelReservationByCodeHandler(ctx context.Context, req *pb.ReservationCodeRequest) (*pb.Response, error) {
+	ctx, span := tracing.Start(ctx, "controllers.CancelReservationByCodeHandler")
+	defer span.End()
+
+	reservation, err := FindReservationByCode(ctx, req.ConfirmationCode, req.Surname, req.Phone)
+	if err != nil {
+		return nil, err
+	}
+	ctx, err = selfServiceContext(ctx, *reservation)
+	if err != nil {
+		return nil, err
+	}
+
+	update := bson.M{"status": "cancelada", "updateat": time.Now()}
+	err = UpdateReservation(ctx, reservation.RestaurantId, reservation.ID, update, reservation.Version)
+	if err != nil {
+		return &pb.Response{Message: "Failed to cancel reservation", Success: false}, err
+	}
+	return &pb.Response{Message: "Reservation cancelled successfully", Success: true, ConfirmationCode: reservation.ConfirmationCode}, nil
+}
@@ -0,0 +1,393 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	m "ms-reservas/models"
+	pb "ms-reservas/protos_pb/proto"
+	"ms-reservas/tracing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Estados de una serie de reservas
+const (
+	SeriesActive    = "activa"
+	SeriesCancelled = "cancelada"
+)
+
+// CREATE
+func CreateReservationSeriesHandler(ctx context.Context, req *pb.CreateReservationSeriesRequest) (*pb.ReservationSeriesResponse, error) {
+	ctx, span := tracing.Start(ctx, "controllers.CreateReservationSeriesHandler")
+	defer span.End()
+
+	series := m.ReservationSeries{
+		RestaurantId:    req.RestaurantId,
+		UserId:          req.UserId,
+		TableId:         req.TableId,
+		StartDate:       req.StartDate,
+		ReservationTime: req.ReservationTime,
+		GuestCount:      int(req.GuestCount),
+		RecurrenceRule:  req.RecurrenceRule,
+		Status:          SeriesActive,
+		CreateAt:        time.Now(),
+	}
+	if series.UserId == "" {
+		return nil, fmt.Errorf("userID is required")
+	}
+	if series.TableId == "" {
+		return nil, fmt.Errorf("tableID is required")
+	}
+	if _, err := GetRestaurantTable(ctx, series.RestaurantId, series.TableId); err != nil {
+		return nil, err
+	}
+	if series.GuestCount <= 0 {
+		return nil, fmt.Errorf("guestCount must be greater than 0")
+	}
+	restaurant, err := GetRestaurantByID(ctx, series.RestaurantId)
+	if err != nil {
+		return nil, fmt.Errorf("restaurant not found")
+	}
+	// La política se aplica una vez a toda la serie: todas las ocurrencias
+	// se crean con el mismo estado
+	reservationStatus, rejection, err := applyReliabilityPolicy(ctx, restaurant, series.UserId, series.GuestCount, "confirmada")
+	if err != nil {
+		return nil, err
+	}
+	if rejection != "" {
+		return &pb.ReservationSeriesResponse{Message: rejection, Success: false}, nil
+	}
+
+	const dateFormat = "02-01-2006"
+	start, err := time.Parse(dateFormat, series.StartDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid date format, expected dd-mm-yyyy")
+	}
+
+	rule, err := ParseRecurrenceRule(series.RecurrenceRule)
+	if err != nil {
+		return nil, err
+	}
+	dates, err := rule.Occurrences(start)
+	if err != nil {
+		return nil, err
+	}
+	if len(dates) == 0 {
+		return nil, fmt.Errorf("recurrence rule generates no occurrences")
+	}
+
+	// Primero se comprueban todas las fechas para informar de cada conflicto
+	var occurrences []*pb.OccurrenceResult
+	conflicts := 0
+	for _, date := range dates {
+		reservationDate := date.Format(dateFormat)
+		exists, err := ReservationExists(ctx, series.TableId, reservationDate, series.ReservationTime)
+		if err != nil {
+			return nil, err
+		}
+		occurrence := &pb.OccurrenceResult{ReservationDate: reservationDate, Conflict: exists}
+		if exists {
+			occurrence.Message = "table already reserved or held for this slot"
+			conflicts++
+		}
+		occurrences = append(occurrences, occurrence)
+	}
+
+	if conflicts > 0 && !req.SkipConflicts {
+		return &pb.ReservationSeriesResponse{
+			Message:     fmt.Sprintf("%d of %d occurrences conflict with existing reservations", conflicts, len(dates)),
+			Success:     false,
+			Occurrences: occurrences,
+		}, nil
+	}
+	if conflicts == len(dates) {
+		return &pb.ReservationSeriesResponse{
+			Message:     "All occurrences conflict with existing reservations",
+			Success:     false,
+			Occurrences: occurrences,
+		}, nil
+	}
+
+	seriesID, err := CreateSeries(ctx, series)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, occurrence := range occurrences {
+		if occurrence.Conflict {
+			continue
+		}
+		reservation := m.Reservation{
+			RestaurantId:    series.RestaurantId,
+			UserId:          series.UserId,
+			TableId:         series.TableId,
+			ReservationDate: occurrence.ReservationDate,
+			ReservationTime: series.ReservationTime,
+			GuestCount:      series.GuestCount,
+			Status:          reservationStatus,
+			Source:          SourceBooking,
+			SeriesId:        seriesID,
+			OccurrenceIndex: i,
+			CreateAt:        time.Now(),
+		}
+		// El depósito depende de la fecha, así que se pide por ocurrencia
+		if awaitingGuest(reservationStatus) {
+			if cents := requiredDeposit(restaurant, occurrence.ReservationDate, series.GuestCount); cents > 0 {
+				if err = requestDeposit(ctx, restaurant, &reservation, cents); err != nil {
+					occurrence.Conflict = true
+					occurrence.Message = err.Error()
+					continue
+				}
+			}
+		}
+		reservationID, err := CreateRes(ctx, reservation)
+		if err != nil {
+			settleDeposit(ctx, reservation)
+			occurrence.Conflict = true
+			occurrence.Message = err.Error()
+			continue
+		}
+		occurrence.ReservationId = reservationID
+	}
+
+	err = UpdateTableIsReserved(ctx, series.TableId, true)
+	if err != nil {
+		return nil, err
+	}
+
+	message := "Reservation series created successfully"
+	if conflicts > 0 {
+		message = fmt.Sprintf("Reservation series created, %d conflicting occurrences skipped", conflicts)
+	}
+	return &pb.ReservationSeriesResponse{
+		Message:     message,
+		Success:     true,
+		SeriesId:    seriesID,
+		Occurrences: occurrences,
+	}, nil
+}
+
+func CreateSeries(ctx context.Context, series m.ReservationSeries) (string, error) {
+	ctx, span := tracing.Start(ctx, "controllers.CreateSeries")
+	defer span.End()
+
+	collection := mongoClient.Database("reservations-db").Collection("series")
+	result, err := collection.InsertOne(ctx, series)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to insert reservation series", "error", err)
+		return "", err
+	}
+	return result.InsertedID.(primitive.ObjectID).Hex(), nil
+}
+
+func GetSeriesByID(ctx context.Context, id string) (*m.ReservationSeries, error) {
+	ctx, span := tracing.Start(ctx, "controllers.GetSeriesByID")
+	defer span.End()
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		slog.WarnContext(ctx, "invalid id format", "error", err)
+		return nil, err
+	}
+
+	collection := mongoClient.Database("reservations-db").Collection("series")
+	var series m.ReservationSeries
+	err = collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&series)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to find reservation series", "error", err)
+		return nil, err
+	}
+	return &series, nil
+}
+
+// UPDATE
+func UpdateReservationSeriesHandler(ctx context.Context, req *pb.UpdateReservationSeriesRequest) (*pb.ReservationSeriesResponse, error) {
+	ctx, span := tracing.Start(ctx, "controllers.UpdateReservationSeriesHandler")
+	defer span.End()
+
+	update := bson.M{}
+	if req.TableId != "" {
+		if _, err := GetRestaurantTable(ctx, req.RestaurantId, req.TableId); err != nil {
+			return nil, err
+		}
+		update["tableid"] = req.TableId
+	}
+	if req.ReservationTime != "" {
+		const timeFormat = "15:04"
+		reservationTime, err := time.Parse(timeFormat, req.ReservationTime)
+		if err != nil {
+			return nil, fmt.Errorf("invalid time format, expected HH:MM")
+		}
+		if reservationTime.Minute() != 0 {
+			return nil, fmt.Errorf("reservation time must be end in 00")
+		}
+		update["reservationtime"] = req.ReservationTime
+	}
+	if req.GuestCount != 0 {
+		update["guestcount"] = int(req.GuestCount)
+	}
+	if req.Status != "" {
+		if !validStatuses[req.Status] {
+			return nil, fmt.Errorf(invalidStatusMessage)
+		}
+		update["status"] = req.Status
+		if req.Status == "sentada" {
+			update["seatedat"] = time.Now()
+		}
+	}
+	if len(update) == 0 {
+		return nil, fmt.Errorf("nothing to update")
+	}
+
+	return updateSeries(ctx, req.RestaurantId, req.SeriesId, req.ReservationId, req.Scope, update)
+}
+
+// CANCEL
+func CancelReservationSeriesHandler(ctx context.Context, req *pb.CancelReservationSeriesRequest) (*pb.ReservationSeriesResponse, error) {
+	ctx, span := tracing.Start(ctx, "controllers.CancelReservationSeriesHandler")
+	defer span.End()
+
+	return updateSeries(ctx, req.RestaurantId, req.SeriesId, req.ReservationId, req.Scope, bson.M{"status": "cancelada"})
+}
+
+// updateSeries aplica update a las reservas de la serie según el alcance:
+// solo la ocurrencia indicada, esa y las siguientes, o la serie completa.
+// Las ocurrencias cuyo cambio de mesa u hora choca con otra reserva se
+// informan y se dejan sin modificar.
+func updateSeries(ctx context.Context, restaurantID, seriesID, reservationID string, scope pb.SeriesScope, update bson.M) (*pb.ReservationSeriesResponse, error) {
+	ctx, span := tracing.Start(ctx, "controllers.updateSeries")
+	defer span.End()
+
+	if restaurantID == "" {
+		return nil, fmt.Errorf("restaurantID is required")
+	}
+	if seriesID == "" {
+		return nil, fmt.Errorf("seriesID is required")
+	}
+
+	filter := bson.M{"restaurantid": restaurantID, "seriesid": seriesID, "status": bson.M{"$in": bson.A{"confirmada", StatusPendingConfirmation, StatusPendingPayment}}, "deleted": notDeleted}
+	switch scope {
+	case pb.SeriesScope_SERIES_SCOPE_SINGLE, pb.SeriesScope_SERIES_SCOPE_FOLLOWING:
+		anchor, err := GetReservationByID(ctx, restaurantID, reservationID)
+		if err != nil {
+			return nil, err
+		}
+		if anchor.SeriesId != seriesID {
+			return nil, fmt.Errorf("reservation does not belong to series")
+		}
+		if scope == pb.SeriesScope_SERIES_SCOPE_SINGLE {
+			objectID, _ := primitive.ObjectIDFromHex(reservationID)
+			filter["_id"] = objectID
+		} else {
+			filter["occurrenceindex"] = bson.M{"$gte": anchor.OccurrenceIndex}
+		}
+	case pb.SeriesScope_SERIES_SCOPE_ALL:
+	default:
+		return nil, fmt.Errorf("invalid series scope")
+	}
+
+	collection := mongoClient.Database("reservations-db").Collection("reservations")
+	opts := options.Find().SetSort(bson.D{{Key: "occurrenceindex", Value: 1}})
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to find series reservations", "error", err)
+		return nil, err
+	}
+	var reservations []m.Reservation
+	if err = cursor.All(ctx, &reservations); err != nil {
+		slog.ErrorContext(ctx, "failed to decode series reservations", "error", err)
+		return nil, err
+	}
+	if len(reservations) == 0 {
+		return nil, fmt.Errorf("no active reservations in scope")
+	}
+
+	var occurrences []*pb.OccurrenceResult
+	conflicts := 0
+	for _, reservation := range reservations {
+		occurrence := &pb.OccurrenceResult{ReservationDate: reservation.ReservationDate, ReservationId: reservation.ID}
+		occurrences = append(occurrences, occurrence)
+
+		after := reservation
+		if t, ok := update["tableid"].(string); ok {
+			after.TableId = t
+		}
+		if t, ok := update["reservationtime"].(string); ok {
+			after.ReservationTime = t
+		}
+		if g, ok := update["guestcount"].(int); ok {
+			after.GuestCount = g
+		}
+		response, err := checkReservationChange(ctx, reservation, after)
+		if err != nil {
+			return nil, err
+		}
+		if response != nil {
+			occurrence.Conflict = true
+			occurrence.Message = response.Message
+			conflicts++
+			continue
+		}
+
+		occurrenceUpdate := bson.M{"updateat": time.Now()}
+		for key, value := range update {
+			occurrenceUpdate[key] = value
+		}
+		if err := UpdateReservation(ctx, restaurantID, reservation.ID, occurrenceUpdate, 0); err != nil {
+			occurrence.Conflict = true
+			occurrence.Message = err.Error()
+			conflicts++
+		}
+	}
+
+	if scope == pb.SeriesScope_SERIES_SCOPE_ALL {
+		seriesUpdate := bson.M{"updateat": time.Now()}
+		for key, value := range update {
+			if key == "status" {
+				if value == "cancelada" {
+					seriesUpdate["status"] = SeriesCancelled
+				}
+				continue
+			}
+			seriesUpdate[key] = value
+		}
+		if err := UpdateSeries(ctx, seriesID, seriesUpdate); err != nil {
+			return nil, err
+		}
+	}
+
+	message := fmt.Sprintf("%d occurrences updated", len(reservations)-conflicts)
+	if conflicts > 0 {
+		message = fmt.Sprintf("%d occurrences updated, %d conflicting occurrences skipped", len(reservations)-conflicts, conflicts)
+	}
+	return &pb.ReservationSeriesResponse{
+		Message:     message,
+		Success:     conflicts == 0,
+		SeriesId:    seriesID,
+		Occurrences: occurrences,
+	}, nil
+}
+
+func UpdateSeries(ctx context.Context, id string, update bson.M) error {
+	ctx, span := tracing.Start(ctx, "controllers.UpdateSeries")
+	defer span.End()
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		slog.WarnContext(ctx, "invalid id format", "error", err)
+		return err
+	}
+
+	collection := mongoClient.Database("reservations-db").Collection("series")
+	_, err = collection.UpdateOne(ctx, bson.M{"_id": objectID}, bson.M{"$set": update})
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to update reservation series", "error", err)
+		return err
+	}
+	return nil
+}
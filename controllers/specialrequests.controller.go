@@ -0,0 +1,156 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	m "ms-reservas/models"
+	pb "ms-reservas/protos_pb/proto"
+	"ms-reservas/tracing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Ocasiones que puede indicar una reserva
+const (
+	OccasionBirthday    = "cumpleaños"
+	OccasionAnniversary = "aniversario"
+	OccasionBusiness    = "negocios"
+	OccasionDate        = "cita"
+	OccasionCelebration = "celebración"
+	OccasionOther       = "otro"
+)
+
+var validOccasions = []string{OccasionBirthday, OccasionAnniversary, OccasionBusiness, OccasionDate, OccasionCelebration, OccasionOther}
+
+const (
+	maxDietaryRestrictions = 10
+	maxNotesLength         = 500
+)
+
+// normalizeDietaryRestrictions recorta y pasa a minúsculas las
+// restricciones alimentarias y descarta vacías y repetidas, para que las
+// búsquedas por restricción coincidan con lo que escribió cada cliente.
+func normalizeDietaryRestrictions(restrictions []string) []string {
+	var normalized []string
+	for _, restriction := range restrictions {
+		restriction = strings.ToLower(strings.TrimSpace(restriction))
+		if restriction != "" && !slices.Contains(normalized, restriction) {
+			normalized = append(normalized, restriction)
+		}
+	}
+	return normalized
+}
+
+func validateSpecialRequests(reservation m.Reservation) error {
+	if reservation.Occasion != "" && !slices.Contains(validOccasions, reservation.Occasion) {
+		return fmt.Errorf("invalid occasion, expected one of %v", validOccasions)
+	}
+	if len(reservation.DietaryRestrictions) > maxDietaryRestrictions {
+		return fmt.Errorf("too many dietary restrictions, at most %d", maxDietaryRestrictions)
+	}
+	for _, restriction := range reservation.DietaryRestrictions {
+		if len(restriction) > 100 {
+			return fmt.Errorf("dietary restriction too long")
+		}
+	}
+	if len(reservation.Notes) > maxNotesLength || len(reservation.StaffNotes) > maxNotesLength {
+		return fmt.Errorf("notes cannot exceed %d characters", maxNotesLength)
+	}
+	return nil
+}
+
+// ReservationSearch son los filtros de SearchReservations. Los campos vacíos
+// no filtran.
+type ReservationSearch struct {
+	RestaurantId           string
+	ReservationDate        string
+	Status                 string
+	Occasion               string
+	DietaryRestriction     string
+	HasDietaryRestrictions bool
+	HasNotes               bool
+}
+
+// SEARCH
+func SearchReservationsHandler(ctx context.Context, req *pb.SearchReservationsRequest) (*pb.Reservations, error) {
+	ctx, span := tracing.Start(ctx, "controllers.SearchReservationsHandler")
+	defer span.End()
+
+	reservations, err := SearchReservations(ctx, ReservationSearch{
+		RestaurantId:           req.RestaurantId,
+		ReservationDate:        req.ReservationDate,
+		Status:                 req.Status,
+		Occasion:               req.Occasion,
+		DietaryRestriction:     req.DietaryRestriction,
+		HasDietaryRestrictions: req.HasDietaryRestrictions,
+		HasNotes:               req.HasNotes,
+	})
+	if err != nil {
+		return nil, err
+	}
+	guests := guestSummaries(ctx, reservations)
+	var pbReservations []*pb.Reservation
+	for _, reservation := range reservations {
+		pbReservations = append(pbReservations, toPbReservation(reservation, guests[reservation.UserId]))
+	}
+	return &pb.Reservations{Reservations: pbReservations}, nil
+}
+
+// SearchReservations devuelve las reservas del restaurante que cumplen los
+// filtros, ordenadas por fecha y hora.
+func SearchReservations(ctx context.Context, search ReservationSearch) ([]m.Reservation, error) {
+	ctx, span := tracing.Start(ctx, "controllers.SearchReservations")
+	defer span.End()
+
+	if search.RestaurantId == "" {
+		return nil, fmt.Errorf("restaurantID is required")
+	}
+	filter := bson.M{"restaurantid": search.RestaurantId, "deleted": notDeleted}
+	if search.ReservationDate != "" {
+		filter["reservationdate"] = search.ReservationDate
+	}
+	if search.Status != "" {
+		if !validStatuses[search.Status] {
+			return nil, fmt.Errorf(invalidStatusMessage)
+		}
+		filter["status"] = search.Status
+	}
+	if search.Occasion != "" {
+		filter["occasion"] = search.Occasion
+	}
+	if search.DietaryRestriction != "" {
+		filter["dietaryrestrictions"] = strings.ToLower(strings.TrimSpace(search.DietaryRestriction))
+	} else if search.HasDietaryRestrictions {
+		filter["dietaryrestrictions.0"] = bson.M{"$exists": true}
+	}
+	if search.HasNotes {
+		filter["notes"] = bson.M{"$gt": ""}
+	}
+
+	collection := mongoClient.Database("reservations-db").Collection("reservations")
+	cursor, err := collection.Find(ctx, filter)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to search reservations", "error", err)
+		return nil, err
+	}
+	var reservations []m.Reservation
+	if err = cursor.All(ctx, &reservations); err != nil {
+		slog.ErrorContext(ctx, "failed to decode reservations", "error", err)
+		return nil, err
+	}
+	// reservationdate es dd-mm-yyyy, así que se ordena por la fecha parseada
+	slices.SortFunc(reservations, func(a, b m.Reservation) int {
+		dateA, _ := time.Parse("02-01-2006", a.ReservationDate)
+		dateB, _ := time.Parse("02-01-2006", b.ReservationDate)
+		if c := dateA.Compare(dateB); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ReservationTime, b.ReservationTime)
+	})
+	return reservations, nil
+}
@@ -2,83 +2,150 @@ package controllers
 
 import (
 	"context"
-	"log"
+	"fmt"
+	"log/slog"
 	"time"
 
+	"ms-reservas/metrics"
 	m "ms-reservas/models"
 	pb "ms-reservas/protos_pb/proto"
+	"ms-reservas/tracing"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 // CREATE
-func CreateTableHandler(req *pb.CreateTableRequest) (*pb.Response, error) {
+func CreateTableHandler(ctx context.Context, req *pb.CreateTableRequest) (*pb.Response, error) {
+	ctx, span := tracing.Start(ctx, "controllers.CreateTableHandler")
+	defer span.End()
+
 	if (req.Number == 0) || (req.Capacity == 0) {
 		return &pb.Response{Message: "number and capacity are required", Success: false}, nil
 	}
 	if (req.Number < 0) || (req.Capacity < 0) {
 		return &pb.Response{Message: "number and capacity must be greater than 0", Success: false}, nil
 	}
+	if _, err := GetRestaurantByID(ctx, req.RestaurantId); err != nil {
+		return &pb.Response{Message: "restaurant not found", Success: false}, err
+	}
 	table := m.Table{
-		Number:     int(req.Number),
-		Capacity:   int(req.Capacity),
-		IsReserved: req.IsReserved,
+		RestaurantId: req.RestaurantId,
+		Number:       int(req.Number),
+		Capacity:     int(req.Capacity),
+		IsReserved:   req.IsReserved,
 	}
 
-	err := CreateTable(table)
+	err := CreateTable(ctx, table)
 	if err != nil {
 		return &pb.Response{Message: "failed to create table", Success: false}, err
 	}
 	return &pb.Response{Message: "table created successfully", Success: true}, nil
 }
 
-func CreateTable(table m.Table) error {
+func CreateTable(ctx context.Context, table m.Table) error {
+	ctx, span := tracing.Start(ctx, "controllers.CreateTable")
+	defer span.End()
+
 	collection := mongoClient.Database("reservations-db").Collection("tables")
-	_, err := collection.InsertOne(context.TODO(), table)
+	result, err := collection.InsertOne(ctx, table)
 	if err != nil {
-		log.Printf("failed to insert table: %v", err)
+		slog.ErrorContext(ctx, "failed to insert table", "error", err)
 		return err
 	}
+	table.ID = result.InsertedID.(primitive.ObjectID).Hex()
+	recordAudit(ctx, AuditEntityTable, table.ID, table.RestaurantId, AuditCreate, nil, table)
 	return nil
 }
 
 // GET ALL
-func GetTablesHandler(req *pb.Empty) (*pb.Tables, error) {
-	tables, err := GetTables()
+func GetTablesHandler(ctx context.Context, req *pb.GetTablesRequest) (*pb.Tables, error) {
+	ctx, span := tracing.Start(ctx, "controllers.GetTablesHandler")
+	defer span.End()
+
+	tables, err := GetTables(ctx, req.RestaurantId)
 	if err != nil {
 		return nil, err
 	}
 	var pbTables []*pb.Table
 	for _, table := range tables {
 		pbTables = append(pbTables, &pb.Table{
-			Id:         table.ID,
-			Number:     int32(table.Number),
-			Capacity:   int32(table.Capacity),
-			IsReserved: table.IsReserved,
+			Id:           table.ID,
+			RestaurantId: table.RestaurantId,
+			Number:       int32(table.Number),
+			Capacity:     int32(table.Capacity),
+			IsReserved:   table.IsReserved,
 		})
 	}
 	return &pb.Tables{Tables: pbTables}, nil
 }
 
-func GetTables() ([]m.Table, error) {
+func GetTables(ctx context.Context, restaurantID string) ([]m.Table, error) {
+	ctx, span := tracing.Start(ctx, "controllers.GetTables")
+	defer span.End()
+
+	if restaurantID == "" {
+		return nil, fmt.Errorf("restaurantID is required")
+	}
+
 	collection := mongoClient.Database("reservations-db").Collection("tables")
-	cursor, err := collection.Find(context.TODO(), bson.M{})
+	cursor, err := collection.Find(ctx, bson.M{"restaurantid": restaurantID})
 
 	if err != nil {
-		log.Printf("failed to find tables: %v", err)
+		slog.ErrorContext(ctx, "failed to find tables", "error", err)
 		return nil, err
 	}
 	var tables []m.Table
-	if err = cursor.All(context.TODO(), &tables); err != nil {
-		log.Printf("failed to decode tables: %v", err)
+	if err = cursor.All(ctx, &tables); err != nil {
+		slog.ErrorContext(ctx, "failed to decode tables", "error", err)
 		return nil, err
 	}
 	return tables, nil
 }
 
+func GetTableByID(ctx context.Context, id string) (*m.Table, error) {
+	ctx, span := tracing.Start(ctx, "controllers.GetTableByID")
+	defer span.End()
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		slog.WarnContext(ctx, "invalid id format", "error", err)
+		return nil, err
+	}
+
+	collection := mongoClient.Database("reservations-db").Collection("tables")
+	var table m.Table
+	err = collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&table)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to find table", "error", err)
+		return nil, err
+	}
+	return &table, nil
+}
+
+// GetRestaurantTable devuelve la mesa solo si pertenece al restaurante, de
+// modo que una sucursal no pueda reservar mesas de otra.
+func GetRestaurantTable(ctx context.Context, restaurantID, tableID string) (*m.Table, error) {
+	ctx, span := tracing.Start(ctx, "controllers.GetRestaurantTable")
+	defer span.End()
+
+	table, err := GetTableByID(ctx, tableID)
+	if err != nil {
+		return nil, err
+	}
+	if restaurantID == "" || table.RestaurantId != restaurantID {
+		return nil, fmt.Errorf("table not found in restaurant")
+	}
+	return table, nil
+}
+
 // UPDATE
-func UpdateTableHandler(req *pb.UpdateTableRequest) (*pb.Response, error) {
+func UpdateTableHandler(ctx context.Context, req *pb.UpdateTableRequest) (*pb.Response, error) {
+	ctx, span := tracing.Start(ctx, "controllers.UpdateTableHandler")
+	defer span.End()
+
 	id := req.Id
 
 	update := bson.M{}
@@ -88,75 +155,100 @@ func UpdateTableHandler(req *pb.UpdateTableRequest) (*pb.Response, error) {
 	update["is_reserved"] = req.IsReserved
 	update["update_at"] = time.Now()
 
-	err := UpdateTable(id, update)
+	err := UpdateTable(ctx, req.RestaurantId, id, update)
 	if err != nil {
 		return &pb.Response{Message: "failed to update table", Success: false}, err
 	}
 	return &pb.Response{Message: "table updated successfully", Success: true}, nil
 }
 
-func UpdateTable(id string, update bson.M) error {
+func UpdateTable(ctx context.Context, restaurantID, id string, update bson.M) error {
+	ctx, span := tracing.Start(ctx, "controllers.UpdateTable")
+	defer span.End()
+
+	if restaurantID == "" {
+		return fmt.Errorf("restaurantID is required")
+	}
 	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		log.Printf("invalid id format: %v", err)
+		slog.WarnContext(ctx, "invalid id format", "error", err)
 		return err
 	}
 
-	collection := mongoClient.Database("reservations-db").Collection("tables")
-	_, err = collection.UpdateOne(context.TODO(), bson.M{"_id": objectID}, bson.M{"$set": update})
-	if err != nil {
-		log.Printf("failed to update table: %v", err)
-		return err
+	err = updateTableAudited(ctx, bson.M{"_id": objectID, "restaurantid": restaurantID}, update)
+	if err == mongo.ErrNoDocuments {
+		return fmt.Errorf("table not found in restaurant")
 	}
-	return nil
+	return err
 }
 
 // GET AVAILABLE TABLES
-func GetAvailableTablesHandler(req *pb.GetAvailableTablesRequest) (*pb.Tables, error) {
+func GetAvailableTablesHandler(ctx context.Context, req *pb.GetAvailableTablesRequest) (*pb.Tables, error) {
+	ctx, span := tracing.Start(ctx, "controllers.GetAvailableTablesHandler")
+	defer span.End()
+
 	date := req.ReservationDate
-	tables, err := GetAvailableTables(date)
+	tables, err := GetAvailableTables(ctx, req.RestaurantId, date)
 	if err != nil {
 		return nil, err
 	}
 	var pbTables []*pb.Table
 	for _, table := range tables {
 		pbTables = append(pbTables, &pb.Table{
-			Id:         table.ID,
-			Number:     int32(table.Number),
-			Capacity:   int32(table.Capacity),
-			IsReserved: table.IsReserved,
+			Id:           table.ID,
+			RestaurantId: table.RestaurantId,
+			Number:       int32(table.Number),
+			Capacity:     int32(table.Capacity),
+			IsReserved:   table.IsReserved,
 		})
 	}
 	return &pb.Tables{Tables: pbTables}, nil
 }
 
-func GetAvailableTables(date string) ([]m.Table, error) {
+func GetAvailableTables(ctx context.Context, restaurantID, date string) ([]m.Table, error) {
+	ctx, span := tracing.Start(ctx, "controllers.GetAvailableTables")
+	defer span.End()
+
+	if restaurantID == "" {
+		return nil, fmt.Errorf("restaurantID is required")
+	}
+	start := time.Now()
+
 	collectionReservations := mongoClient.Database("reservations-db").Collection("reservations")
-	cursorReservations, err := collectionReservations.Find(context.TODO(), bson.M{"reservationdate": date})
+	cursorReservations, err := collectionReservations.Find(ctx, bson.M{
+		"restaurantid":    restaurantID,
+		"reservationdate": date,
+		"status":          bson.M{"$ne": "cancelada"},
+		"deleted":         notDeleted,
+	})
 	if err != nil {
-		log.Printf("failed to find reservations: %v", err)
+		slog.ErrorContext(ctx, "failed to find reservations", "error", err)
 		return nil, err
 	}
 	var reservations []m.Reservation
-	if err = cursorReservations.All(context.TODO(), &reservations); err != nil {
-		log.Printf("failed to decode reservations: %v", err)
+	if err = cursorReservations.All(ctx, &reservations); err != nil {
+		slog.ErrorContext(ctx, "failed to decode reservations", "error", err)
 		return nil, err
 	}
 
-	reservedTables := make(map[string]bool)
+	reservedTables, err := getHeldTables(ctx, date, nil)
+	if err != nil {
+		return nil, err
+	}
+	documents := len(reservations) + len(reservedTables)
 	for _, reservation := range reservations {
 		reservedTables[reservation.TableId] = true
 	}
 
 	collectionTables := mongoClient.Database("reservations-db").Collection("tables")
-	cursorTables, err := collectionTables.Find(context.TODO(), bson.M{})
+	cursorTables, err := collectionTables.Find(ctx, bson.M{"restaurantid": restaurantID})
 	if err != nil {
-		log.Printf("failed to find tables: %v", err)
+		slog.ErrorContext(ctx, "failed to find tables", "error", err)
 		return nil, err
 	}
 	var tables []m.Table
-	if err = cursorTables.All(context.TODO(), &tables); err != nil {
-		log.Printf("failed to decode tables: %v", err)
+	if err = cursorTables.All(ctx, &tables); err != nil {
+		slog.ErrorContext(ctx, "failed to decode tables", "error", err)
 		return nil, err
 	}
 
@@ -167,22 +259,132 @@ func GetAvailableTables(date string) ([]m.Table, error) {
 		}
 	}
 
+	metrics.ObserveAvailabilityQuery(time.Since(start), documents+len(tables))
 	return availableTables, nil
 }
 
-func UpdateTableIsReserved(tableID string, isReserved bool) error {
+func UpdateTableIsReserved(ctx context.Context, tableID string, isReserved bool) error {
+	ctx, span := tracing.Start(ctx, "controllers.UpdateTableIsReserved")
+	defer span.End()
+
 	objectId, err := primitive.ObjectIDFromHex(tableID)
 	if err != nil {
-		log.Printf("failed to convert table id to object id: %v", err)
+		slog.ErrorContext(ctx, "failed to convert table id to object id", "error", err)
 	}
 
-	collection := mongoClient.Database("reservations-db").Collection("tables")
 	update := bson.M{"isreserved": isReserved}
-	_, err = collection.UpdateOne(context.TODO(), bson.M{"_id": objectId}, bson.M{"$set": update})
+	err = updateTableAudited(ctx, bson.M{"_id": objectId}, update)
+	if err != nil && err != mongo.ErrNoDocuments {
+		slog.ErrorContext(ctx, "failed to update table status", "error", err)
+		return err
+	}
+	return nil
+}
 
+// updateTableAudited aplica update a la mesa que cumpla filter y registra
+// el documento antes y después del cambio. Los documentos se auditan tal
+// cual están guardados, sin pasar por m.Table.
+func updateTableAudited(ctx context.Context, filter, update bson.M) error {
+	collection := mongoClient.Database("reservations-db").Collection("tables")
+	var before, after bson.Raw
+	err := collection.FindOneAndUpdate(ctx, filter, bson.M{"$set": update}).Decode(&before)
+	if err == mongo.ErrNoDocuments {
+		return err
+	}
 	if err != nil {
-		log.Printf("failed to update table status: %v", err)
+		slog.ErrorContext(ctx, "failed to update table", "error", err)
+		return err
+	}
+	tableID := before.Lookup("_id").ObjectID()
+	if err = collection.FindOne(ctx, bson.M{"_id": tableID}).Decode(&after); err != nil {
+		slog.ErrorContext(ctx, "failed to find updated table", "error", err)
 		return err
 	}
+	restaurantID, _ := before.Lookup("restaurantid").StringValueOK()
+	recordAudit(ctx, AuditEntityTable, tableID.Hex(), restaurantID, AuditUpdate, before, after)
 	return nil
 }
+
+// AssignTable elige la mesa más pequeña en la que quepa el grupo y que no
+// tenga reservas activas en ninguna de las franjas indicadas. Devuelve nil
+// si no hay ninguna libre.
+func AssignTable(ctx context.Context, restaurantID, reservationDate string, slots []string, guestCount int) (*m.Table, error) {
+	ctx, span := tracing.Start(ctx, "controllers.AssignTable")
+	defer span.End()
+
+	return assignTable(ctx, restaurantID, reservationDate, slots, guestCount, nil)
+}
+
+func assignTable(ctx context.Context, restaurantID, reservationDate string, slots []string, guestCount int, busy map[string]bool) (*m.Table, error) {
+	ctx, span := tracing.Start(ctx, "controllers.assignTable")
+	defer span.End()
+
+	if restaurantID == "" {
+		return nil, fmt.Errorf("restaurantID is required")
+	}
+
+	collectionReservations := mongoClient.Database("reservations-db").Collection("reservations")
+	cursorReservations, err := collectionReservations.Find(ctx, bson.M{
+		"restaurantid":    restaurantID,
+		"reservationdate": reservationDate,
+		"$or": bson.A{
+			bson.M{"reservationtime": bson.M{"$in": slots}},
+			bson.M{"blockedslots": bson.M{"$in": slots}},
+		},
+		"status":  bson.M{"$nin": []string{"cancelada", "completada"}},
+		"deleted": notDeleted,
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to find reservations", "error", err)
+		return nil, err
+	}
+	var reservations []m.Reservation
+	if err = cursorReservations.All(ctx, &reservations); err != nil {
+		slog.ErrorContext(ctx, "failed to decode reservations", "error", err)
+		return nil, err
+	}
+
+	reservedTables, err := getHeldTables(ctx, reservationDate, slots)
+	if err != nil {
+		return nil, err
+	}
+	for tableID := range busy {
+		reservedTables[tableID] = true
+	}
+	for _, reservation := range reservations {
+		reservedTables[reservation.TableId] = true
+	}
+
+	tables, err := getTablesForParty(ctx, restaurantID, guestCount)
+	if err != nil {
+		return nil, err
+	}
+	for _, table := range tables {
+		if !reservedTables[table.ID] {
+			return &table, nil
+		}
+	}
+	return nil, nil
+}
+
+// getTablesForParty devuelve las mesas con capacidad suficiente, de menor
+// a mayor capacidad.
+func getTablesForParty(ctx context.Context, restaurantID string, guestCount int) ([]m.Table, error) {
+	ctx, span := tracing.Start(ctx, "controllers.getTablesForParty")
+	defer span.End()
+
+	collection := mongoClient.Database("reservations-db").Collection("tables")
+	opts := options.Find().SetSort(bson.D{{Key: "capacity", Value: 1}, {Key: "number", Value: 1}})
+	filter := bson.M{"restaurantid": restaurantID, "capacity": bson.M{"$gte": guestCount}}
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to find tables", "error", err)
+		return nil, err
+	}
+	var tables []m.Table
+	if err = cursor.All(ctx, &tables); err != nil {
+		slog.ErrorContext(ctx, "failed to decode tables", "error", err)
+		return nil, err
+	}
+	return tables, nil
+}
@@ -0,0 +1,528 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	m "ms-reservas/models"
+	pb "ms-reservas/protos_pb/proto"
+	"ms-reservas/tracing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Estados de una entrada en la lista de espera
+const (
+	WaitlistWaiting   = "en_espera"
+	WaitlistOffered   = "ofrecida"
+	WaitlistAccepted  = "aceptada"
+	WaitlistPromoted  = "promovida"
+	WaitlistExpired   = "expirada"
+	WaitlistCancelled = "cancelada"
+)
+
+// waitlistOfferTTL lee WAITLIST_OFFER_TTL_MINUTES; por defecto 15 minutos.
+func waitlistOfferTTL() time.Duration {
+	return envMinutes("WAITLIST_OFFER_TTL_MINUTES", 15*time.Minute)
+}
+
+// JOIN
+func JoinWaitlistHandler(ctx context.Context, req *pb.JoinWaitlistRequest) (*pb.WaitlistEntry, error) {
+	ctx, span := tracing.Start(ctx, "controllers.JoinWaitlistHandler")
+	defer span.End()
+
+	entry := m.WaitlistEntry{
+		RestaurantId:    req.RestaurantId,
+		UserId:          req.UserId,
+		ReservationDate: req.ReservationDate,
+		ReservationTime: req.ReservationTime,
+		GuestCount:      int(req.GuestCount),
+		AutoPromote:     req.AutoPromote,
+		Status:          WaitlistWaiting,
+		CreateAt:        time.Now(),
+	}
+	id, err := JoinWaitlist(ctx, entry)
+	if err != nil {
+		return nil, err
+	}
+	entry.ID = id
+
+	rank, err := waitlistRank(ctx, entry)
+	if err != nil {
+		return nil, err
+	}
+	return toPbWaitlistEntry(entry, rank), nil
+}
+
+func JoinWaitlist(ctx context.Context, entry m.WaitlistEntry) (string, error) {
+	ctx, span := tracing.Start(ctx, "controllers.JoinWaitlist")
+	defer span.End()
+
+	if entry.UserId == "" {
+		return "", fmt.Errorf("userID is required")
+	}
+	if entry.GuestCount <= 0 {
+		return "", fmt.Errorf("guestCount must be greater than 0")
+	}
+
+	const dateFormat = "02-01-2006"
+	if _, err := time.Parse(dateFormat, entry.ReservationDate); err != nil {
+		return "", fmt.Errorf("invalid date format, expected dd-mm-yyyy")
+	}
+
+	const timeFormat = "15:04"
+	reservationTime, err := time.Parse(timeFormat, entry.ReservationTime)
+	if err != nil {
+		return "", fmt.Errorf("invalid time format, expected HH:MM")
+	}
+	if reservationTime.Minute() != 0 {
+		return "", fmt.Errorf("reservation time must be end in 00")
+	}
+
+	restaurant, err := GetRestaurantByID(ctx, entry.RestaurantId)
+	if err != nil {
+		return "", fmt.Errorf("restaurant not found")
+	}
+	err = ValidateRestaurantSlot(restaurant, entry.ReservationDate, entry.ReservationTime, entry.GuestCount)
+	if err != nil {
+		return "", err
+	}
+
+	collection := mongoClient.Database("reservations-db").Collection("waitlist")
+	result, err := collection.InsertOne(ctx, entry)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to insert waitlist entry", "error", err)
+		return "", err
+	}
+	return result.InsertedID.(primitive.ObjectID).Hex(), nil
+}
+
+// GET
+func GetWaitlistHandler(ctx context.Context, req *pb.GetWaitlistRequest) (*pb.WaitlistEntries, error) {
+	ctx, span := tracing.Start(ctx, "controllers.GetWaitlistHandler")
+	defer span.End()
+
+	entries, err := GetWaitlist(ctx, req.RestaurantId, req.ReservationDate, req.ReservationTime)
+	if err != nil {
+		return nil, err
+	}
+
+	// El ranking se calcula por franja horaria, en orden de llegada
+	ranks := make(map[string]int32)
+	var pbEntries []*pb.WaitlistEntry
+	for _, entry := range entries {
+		var rank int32
+		if entry.Status == WaitlistWaiting {
+			ranks[entry.ReservationTime]++
+			rank = ranks[entry.ReservationTime]
+		}
+		pbEntries = append(pbEntries, toPbWaitlistEntry(entry, rank))
+	}
+	return &pb.WaitlistEntries{Entries: pbEntries}, nil
+}
+
+func GetWaitlist(ctx context.Context, restaurantID, date, reservationTime string) ([]m.WaitlistEntry, error) {
+	ctx, span := tracing.Start(ctx, "controllers.GetWaitlist")
+	defer span.End()
+
+	if restaurantID == "" {
+		return nil, fmt.Errorf("restaurantID is required")
+	}
+
+	filter := bson.M{
+		"restaurantid":    restaurantID,
+		"reservationdate": date,
+		"status":          bson.M{"$in": []string{WaitlistWaiting, WaitlistOffered}},
+	}
+	if reservationTime != "" {
+		filter["reservationtime"] = reservationTime
+	}
+
+	collection := mongoClient.Database("reservations-db").Collection("waitlist")
+	opts := options.Find().SetSort(bson.D{{Key: "createat", Value: 1}})
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to find waitlist entries", "error", err)
+		return nil, err
+	}
+	var entries []m.WaitlistEntry
+	if err = cursor.All(ctx, &entries); err != nil {
+		slog.ErrorContext(ctx, "failed to decode waitlist entries", "error", err)
+		return nil, err
+	}
+	return entries, nil
+}
+
+func GetWaitlistEntryByID(ctx context.Context, restaurantID, id string) (*m.WaitlistEntry, error) {
+	ctx, span := tracing.Start(ctx, "controllers.GetWaitlistEntryByID")
+	defer span.End()
+
+	if restaurantID == "" {
+		return nil, fmt.Errorf("restaurantID is required")
+	}
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		slog.WarnContext(ctx, "invalid id format", "error", err)
+		return nil, err
+	}
+
+	collection := mongoClient.Database("reservations-db").Collection("waitlist")
+	var entry m.WaitlistEntry
+	err = collection.FindOne(ctx, bson.M{"_id": objectID, "restaurantid": restaurantID}).Decode(&entry)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to find waitlist entry", "error", err)
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// ACCEPT
+func AcceptWaitlistOfferHandler(ctx context.Context, req *pb.WaitlistEntryRequest) (*pb.Response, error) {
+	ctx, span := tracing.Start(ctx, "controllers.AcceptWaitlistOfferHandler")
+	defer span.End()
+
+	reservationID, err := AcceptWaitlistOffer(ctx, req.RestaurantId, req.Id)
+	if err != nil {
+		return &pb.Response{Message: "Failed to accept waitlist offer", Success: false}, err
+	}
+	return &pb.Response{Message: fmt.Sprintf("Reservation %s created from waitlist offer", reservationID), Success: true}, nil
+}
+
+func AcceptWaitlistOffer(ctx context.Context, restaurantID, id string) (string, error) {
+	ctx, span := tracing.Start(ctx, "controllers.AcceptWaitlistOffer")
+	defer span.End()
+
+	entry, err := GetWaitlistEntryByID(ctx, restaurantID, id)
+	if err != nil {
+		return "", err
+	}
+	if entry.Status != WaitlistOffered {
+		return "", fmt.Errorf("waitlist entry has no pending offer")
+	}
+	if time.Now().After(entry.OfferExpiresAt) {
+		expireWaitlistOffer(ctx, *entry)
+		return "", fmt.Errorf("waitlist offer has expired")
+	}
+
+	reservationID, err := promoteWaitlistEntry(ctx, *entry, entry.OfferedTableId, WaitlistOffered, WaitlistAccepted)
+	if err != nil {
+		return "", err
+	}
+	return reservationID, nil
+}
+
+// LEAVE
+func LeaveWaitlistHandler(ctx context.Context, req *pb.WaitlistEntryRequest) (*pb.Response, error) {
+	ctx, span := tracing.Start(ctx, "controllers.LeaveWaitlistHandler")
+	defer span.End()
+
+	err := LeaveWaitlist(ctx, req.RestaurantId, req.Id)
+	if err != nil {
+		return &pb.Response{Message: "Failed to leave waitlist", Success: false}, err
+	}
+	return &pb.Response{Message: "Left waitlist successfully", Success: true}, nil
+}
+
+func LeaveWaitlist(ctx context.Context, restaurantID, id string) error {
+	ctx, span := tracing.Start(ctx, "controllers.LeaveWaitlist")
+	defer span.End()
+
+	entry, err := GetWaitlistEntryByID(ctx, restaurantID, id)
+	if err != nil {
+		return err
+	}
+	if entry.Status != WaitlistWaiting && entry.Status != WaitlistOffered {
+		return fmt.Errorf("waitlist entry is no longer active")
+	}
+
+	ok, err := transitionWaitlistEntry(ctx, entry.ID, entry.Status, bson.M{"status": WaitlistCancelled})
+	if err != nil || !ok {
+		return err
+	}
+
+	// Si tenía una oferta pendiente, la mesa pasa al siguiente de la lista
+	if entry.Status == WaitlistOffered {
+		OfferFreedSlot(ctx, entry.OfferedTableId, entry.ReservationDate, entry.ReservationTime)
+	}
+	return nil
+}
+
+// OfferFreedSlot ofrece una mesa recién liberada a la primera entrada en
+// espera de esa franja cuyo grupo quepa en la mesa. Las entradas con
+// AutoPromote reciben la reserva directamente; el resto recibe una oferta
+// que caduca tras WAITLIST_OFFER_TTL_MINUTES.
+func OfferFreedSlot(ctx context.Context, tableID, reservationDate, reservationTime string) {
+	ctx, span := tracing.Start(ctx, "controllers.OfferFreedSlot")
+	defer span.End()
+
+	table, err := GetTableByID(ctx, tableID)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to find freed table", "table_id", tableID, "error", err)
+		return
+	}
+
+	exists, err := ReservationExists(ctx, tableID, reservationDate, reservationTime)
+	if err != nil || exists {
+		return
+	}
+
+	collection := mongoClient.Database("reservations-db").Collection("waitlist")
+	pending, err := collection.CountDocuments(ctx, bson.M{
+		"offeredtableid":  tableID,
+		"reservationdate": reservationDate,
+		"reservationtime": reservationTime,
+		"status":          WaitlistOffered,
+	})
+	if err != nil || pending > 0 {
+		return
+	}
+
+	filter := bson.M{
+		"restaurantid":    table.RestaurantId,
+		"reservationdate": reservationDate,
+		"reservationtime": reservationTime,
+		"guestcount":      bson.M{"$lte": table.Capacity},
+		"status":          WaitlistWaiting,
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "createat", Value: 1}})
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to find waitlist entries", "error", err)
+		return
+	}
+	var entries []m.WaitlistEntry
+	if err = cursor.All(ctx, &entries); err != nil {
+		slog.ErrorContext(ctx, "failed to decode waitlist entries", "error", err)
+		return
+	}
+
+	for _, entry := range entries {
+		if entry.AutoPromote {
+			if _, err := promoteWaitlistEntry(ctx, entry, tableID, WaitlistWaiting, WaitlistPromoted); err != nil {
+				slog.ErrorContext(ctx, "failed to promote waitlist entry", "entry_id", entry.ID, "error", err)
+				continue
+			}
+			return
+		}
+
+		ok, err := transitionWaitlistEntry(ctx, entry.ID, WaitlistWaiting, bson.M{
+			"status":         WaitlistOffered,
+			"offeredtableid": tableID,
+			"offerexpiresat": time.Now().Add(waitlistOfferTTL()),
+		})
+		if err != nil {
+			return
+		}
+		if ok {
+			return
+		}
+	}
+}
+
+// ExpireWaitlistOffers marca como expiradas las ofertas no aceptadas a
+// tiempo y vuelve a ofrecer cada mesa al siguiente de la lista.
+func ExpireWaitlistOffers(ctx context.Context) {
+	ctx, span := tracing.Start(ctx, "controllers.ExpireWaitlistOffers")
+	defer span.End()
+
+	collection := mongoClient.Database("reservations-db").Collection("waitlist")
+	cursor, err := collection.Find(ctx, bson.M{
+		"status":         WaitlistOffered,
+		"offerexpiresat": bson.M{"$lt": time.Now()},
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to find expired waitlist offers", "error", err)
+		return
+	}
+	var entries []m.WaitlistEntry
+	if err = cursor.All(ctx, &entries); err != nil {
+		slog.ErrorContext(ctx, "failed to decode expired waitlist offers", "error", err)
+		return
+	}
+
+	for _, entry := range entries {
+		expireWaitlistOffer(ctx, entry)
+	}
+}
+
+// StartWaitlistSweeper revisa periódicamente las ofertas caducadas hasta
+// que se cancele ctx.
+func StartWaitlistSweeper(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				ExpireWaitlistOffers(ctx)
+			}
+		}
+	}()
+}
+
+func expireWaitlistOffer(ctx context.Context, entry m.WaitlistEntry) {
+	ctx, span := tracing.Start(ctx, "controllers.expireWaitlistOffer")
+	defer span.End()
+
+	ok, err := transitionWaitlistEntry(ctx, entry.ID, WaitlistOffered, bson.M{"status": WaitlistExpired})
+	if err != nil || !ok {
+		return
+	}
+	OfferFreedSlot(ctx, entry.OfferedTableId, entry.ReservationDate, entry.ReservationTime)
+}
+
+// promoteWaitlistEntry crea la reserva de una entrada y la marca con el
+// estado final indicado. La transición es atómica: si otra petición ya
+// cambió el estado de la entrada, no se crea la reserva.
+func promoteWaitlistEntry(ctx context.Context, entry m.WaitlistEntry, tableID, fromStatus, toStatus string) (string, error) {
+	ctx, span := tracing.Start(ctx, "controllers.promoteWaitlistEntry")
+	defer span.End()
+
+	exists, err := ReservationExists(ctx, tableID, entry.ReservationDate, entry.ReservationTime)
+	if err != nil {
+		return "", err
+	}
+	if exists {
+		return "", fmt.Errorf("table is no longer available for this slot")
+	}
+
+	restaurant, err := GetRestaurantByID(ctx, entry.RestaurantId)
+	if err != nil {
+		return "", err
+	}
+	reservationStatus, rejection, err := applyReliabilityPolicy(ctx, restaurant, entry.UserId, entry.GuestCount, "confirmada")
+	if err != nil {
+		return "", err
+	}
+	if rejection != "" {
+		return "", fmt.Errorf("%s", rejection)
+	}
+
+	reservation := m.Reservation{
+		RestaurantId:    entry.RestaurantId,
+		UserId:          entry.UserId,
+		TableId:         tableID,
+		ReservationDate: entry.ReservationDate,
+		ReservationTime: entry.ReservationTime,
+		GuestCount:      entry.GuestCount,
+		Status:          reservationStatus,
+		Source:          SourceBooking,
+		CreateAt:        time.Now(),
+	}
+	reservation, err = prepareReservation(ctx, reservation)
+	if err != nil {
+		return "", err
+	}
+	if awaitingGuest(reservationStatus) {
+		if cents := requiredDeposit(restaurant, entry.ReservationDate, entry.GuestCount); cents > 0 {
+			if err = requestDeposit(ctx, restaurant, &reservation, cents); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	// La entrada, la reserva y su referencia en la entrada se escriben
+	// juntas: si algo falla no queda una reserva sin entrada ni al revés.
+	// Los efectos fuera de la base de datos se ejecutan tras confirmar
+	var created m.Reservation
+	err = withTransaction(ctx, func(ctx context.Context) error {
+		ok, err := transitionWaitlistEntry(ctx, entry.ID, fromStatus, bson.M{"status": toStatus, "offeredtableid": tableID})
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("waitlist entry was modified concurrently")
+		}
+		created, err = insertReservation(ctx, reservation)
+		if err != nil {
+			return err
+		}
+		_, err = transitionWaitlistEntry(ctx, entry.ID, toStatus, bson.M{"reservationid": created.ID})
+		return err
+	})
+	if err != nil {
+		settleDeposit(ctx, reservation)
+		return "", err
+	}
+	reservationCreated(ctx, created)
+
+	if err = UpdateTableIsReserved(ctx, tableID, true); err != nil {
+		return "", err
+	}
+	return created.ID, nil
+}
+
+// transitionWaitlistEntry aplica update solo si la entrada sigue en el
+// estado esperado. Devuelve false si otra petición se adelantó.
+func transitionWaitlistEntry(ctx context.Context, id, fromStatus string, update bson.M) (bool, error) {
+	ctx, span := tracing.Start(ctx, "controllers.transitionWaitlistEntry")
+	defer span.End()
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		slog.WarnContext(ctx, "invalid id format", "error", err)
+		return false, err
+	}
+
+	update["updateat"] = time.Now()
+	collection := mongoClient.Database("reservations-db").Collection("waitlist")
+	err = collection.FindOneAndUpdate(ctx,
+		bson.M{"_id": objectID, "status": fromStatus},
+		bson.M{"$set": update},
+	).Err()
+	if err == mongo.ErrNoDocuments {
+		return false, nil
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to update waitlist entry", "error", err)
+		return false, err
+	}
+	return true, nil
+}
+
+func waitlistRank(ctx context.Context, entry m.WaitlistEntry) (int32, error) {
+	ctx, span := tracing.Start(ctx, "controllers.waitlistRank")
+	defer span.End()
+
+	collection := mongoClient.Database("reservations-db").Collection("waitlist")
+	ahead, err := collection.CountDocuments(ctx, bson.M{
+		"restaurantid":    entry.RestaurantId,
+		"reservationdate": entry.ReservationDate,
+		"reservationtime": entry.ReservationTime,
+		"status":          WaitlistWaiting,
+		"createat":        bson.M{"$lt": entry.CreateAt},
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to compute waitlist rank", "error", err)
+		return 0, err
+	}
+	return int32(ahead) + 1, nil
+}
+
+func toPbWaitlistEntry(entry m.WaitlistEntry, rank int32) *pb.WaitlistEntry {
+	pbEntry := &pb.WaitlistEntry{
+		Id:              entry.ID,
+		RestaurantId:    entry.RestaurantId,
+		UserId:          entry.UserId,
+		ReservationDate: entry.ReservationDate,
+		ReservationTime: entry.ReservationTime,
+		GuestCount:      int32(entry.GuestCount),
+		AutoPromote:     entry.AutoPromote,
+		Status:          entry.Status,
+		Rank:            rank,
+		OfferedTableId:  entry.OfferedTableId,
+		ReservationId:   entry.ReservationId,
+		CreateAt:        entry.CreateAt.Format(time.RFC3339),
+	}
+	if !entry.OfferExpiresAt.IsZero() {
+		pbEntry.OfferExpiresAt = entry.OfferExpiresAt.Format(time.RFC3339)
+	}
+	return pbEntry
+}
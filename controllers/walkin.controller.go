@@ -0,0 +1,281 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"time"
+
+	m "ms-reservas/models"
+	pb "ms-reservas/protos_pb/proto"
+	"ms-reservas/tracing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// turnTime lee TURN_TIME_MINUTES, el tiempo esperado que un grupo ocupa la
+// mesa; por defecto 90 minutos.
+func turnTime() time.Duration {
+	return envMinutes("TURN_TIME_MINUTES", 90*time.Minute)
+}
+
+// WALK-IN
+func WalkInHandler(ctx context.Context, req *pb.WalkInRequest) (*pb.WalkInResponse, error) {
+	ctx, span := tracing.Start(ctx, "controllers.WalkInHandler")
+	defer span.End()
+
+	if req.GuestCount <= 0 {
+		return &pb.WalkInResponse{Message: "guestCount must be greater than 0", Success: false}, nil
+	}
+	userID := req.UserId
+	if userID == "" {
+		userID = SourceWalkIn
+	}
+
+	restaurant, err := GetRestaurantByID(ctx, req.RestaurantId)
+	if err != nil {
+		return &pb.WalkInResponse{Message: "Restaurant not found", Success: false}, err
+	}
+	// Los clientes identificados pasan por la política de fiabilidad como en
+	// una reserva; el estado no cambia porque el grupo se sienta ya
+	if req.UserId != "" {
+		_, rejection, err := applyReliabilityPolicy(ctx, restaurant, req.UserId, int(req.GuestCount), "sentada")
+		if err != nil {
+			return &pb.WalkInResponse{Message: "Failed to check guest reliability", Success: false}, err
+		}
+		if rejection != "" {
+			return &pb.WalkInResponse{Message: rejection, Success: false}, nil
+		}
+	}
+
+	// La hora actual se toma en la zona horaria del restaurante
+	now := time.Now().In(restaurantLocation(restaurant))
+	reservationDate := now.Format("02-01-2006")
+	slots := walkInSlots(now, restaurantTurnTime(restaurant))
+
+	seated, err := getSeatedReservations(ctx, restaurant.ID, reservationDate)
+	if err != nil {
+		return &pb.WalkInResponse{Message: "Failed to check current seatings", Success: false}, err
+	}
+	// Un grupo sentado ocupa la mesa durante su tiempo de mesa; los que
+	// nadie pasó a completada no la bloquean el resto del día
+	busy := make(map[string]bool)
+	for _, reservation := range seated {
+		slot, err := time.ParseInLocation("02-01-2006 15:04", reservation.ReservationDate+" "+reservation.ReservationTime, now.Location())
+		if err == nil && !now.Before(seatedUntil(reservation, slot, restaurant)) {
+			continue
+		}
+		busy[reservation.TableId] = true
+	}
+
+	table, err := assignTable(ctx, restaurant.ID, reservationDate, slots, int(req.GuestCount), busy)
+	if err != nil {
+		return &pb.WalkInResponse{Message: "Failed to assign table", Success: false}, err
+	}
+	if table == nil {
+		wait, err := QuoteWaitTime(ctx, restaurant, now, int(req.GuestCount))
+		if err != nil {
+			return &pb.WalkInResponse{Message: "Failed to estimate wait time", Success: false}, err
+		}
+		return &pb.WalkInResponse{
+			Message:           fmt.Sprintf("No table available, estimated wait %d minutes", wait),
+			Success:           false,
+			QuotedWaitMinutes: int32(wait),
+		}, nil
+	}
+
+	reservation := m.Reservation{
+		RestaurantId:    restaurant.ID,
+		UserId:          userID,
+		TableId:         table.ID,
+		ReservationDate: reservationDate,
+		ReservationTime: slots[0],
+		BlockedSlots:    slots[1:],
+		GuestCount:      int(req.GuestCount),
+		Status:          "sentada",
+		Source:          SourceWalkIn,
+		SeatedAt:        now,
+		CreateAt:        now,
+	}
+	reservationID, err := CreateRes(ctx, reservation)
+	if err != nil {
+		return &pb.WalkInResponse{Message: "Failed to create walk-in reservation", Success: false}, err
+	}
+
+	err = UpdateTableIsReserved(ctx, table.ID, true)
+	if err != nil {
+		return &pb.WalkInResponse{Message: "Failed to update table status", Success: false}, err
+	}
+
+	return &pb.WalkInResponse{
+		Message:       "Walk-in seated successfully",
+		Success:       true,
+		ReservationId: reservationID,
+		TableId:       table.ID,
+	}, nil
+}
+
+// QuoteWaitTime estima en minutos cuándo quedará libre la primera mesa
+// donde quepa el grupo, a partir de los grupos sentados y de las reservas
+// confirmadas que comienzan durante el turno.
+func QuoteWaitTime(ctx context.Context, restaurant *m.Restaurant, now time.Time, guestCount int) (int, error) {
+	ctx, span := tracing.Start(ctx, "controllers.QuoteWaitTime")
+	defer span.End()
+
+	tables, err := getTablesForParty(ctx, restaurant.ID, guestCount)
+	if err != nil {
+		return 0, err
+	}
+	if len(tables) == 0 {
+		return 0, fmt.Errorf("no table fits a party of %d", guestCount)
+	}
+
+	turn := restaurantTurnTime(restaurant)
+	reservationDate := now.Format("02-01-2006")
+	collection := mongoClient.Database("reservations-db").Collection("reservations")
+	cursor, err := collection.Find(ctx, bson.M{
+		"restaurantid":    restaurant.ID,
+		"reservationdate": reservationDate,
+		"deleted":         notDeleted,
+		"$or": []bson.M{
+			{"status": "sentada"},
+			{"status": bson.M{"$in": bson.A{"confirmada", StatusPendingConfirmation}}, "reservationtime": bson.M{"$in": walkInSlots(now, turn)}},
+		},
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to find reservations", "error", err)
+		return 0, err
+	}
+	var reservations []m.Reservation
+	if err = cursor.All(ctx, &reservations); err != nil {
+		slog.ErrorContext(ctx, "failed to decode reservations", "error", err)
+		return 0, err
+	}
+
+	freeAt := make(map[string]time.Time)
+	for _, reservation := range reservations {
+		var end time.Time
+		if reservation.Status == "sentada" {
+			end = reservation.SeatedAt.Add(turn)
+		} else {
+			start, err := time.ParseInLocation("02-01-2006 15:04", reservation.ReservationDate+" "+reservation.ReservationTime, now.Location())
+			if err != nil {
+				continue
+			}
+			end = start.Add(turn)
+		}
+		if end.After(freeAt[reservation.TableId]) {
+			freeAt[reservation.TableId] = end
+		}
+	}
+
+	best := math.MaxInt
+	for _, table := range tables {
+		wait := 0
+		if end, ok := freeAt[table.ID]; ok && end.After(now) {
+			wait = int(math.Ceil(end.Sub(now).Minutes()))
+		}
+		if wait < best {
+			best = wait
+		}
+	}
+	return best, nil
+}
+
+// anonymousWalkIn indica si la reserva es de un walk-in sin cliente
+// identificado. Todos comparten el usuario SourceWalkIn, así que no tienen
+// perfil ni fiabilidad propios.
+func anonymousWalkIn(reservation m.Reservation) bool {
+	return reservation.UserId == SourceWalkIn
+}
+
+// walkInSlots devuelve las franjas horarias que ocuparía un grupo sentado
+// ahora durante un turno completo, empezando por la franja actual.
+func walkInSlots(now time.Time, turn time.Duration) []string {
+	end := now.Add(turn)
+	slot := time.Date(now.Year(), now.Month(), now.Day(), now.Hour(), 0, 0, 0, now.Location())
+	var slots []string
+	for slot.Before(end) && slot.Day() == now.Day() {
+		slots = append(slots, slot.Format("15:04"))
+		slot = slot.Add(time.Hour)
+	}
+	return slots
+}
+
+func getSeatedReservations(ctx context.Context, restaurantID, reservationDate string) ([]m.Reservation, error) {
+	ctx, span := tracing.Start(ctx, "controllers.getSeatedReservations")
+	defer span.End()
+
+	collection := mongoClient.Database("reservations-db").Collection("reservations")
+	cursor, err := collection.Find(ctx, bson.M{
+		"restaurantid":    restaurantID,
+		"reservationdate": reservationDate,
+		"status":          "sentada",
+		"deleted":         notDeleted,
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to find seated reservations", "error", err)
+		return nil, err
+	}
+	var reservations []m.Reservation
+	if err = cursor.All(ctx, &reservations); err != nil {
+		slog.ErrorContext(ctx, "failed to decode seated reservations", "error", err)
+		return nil, err
+	}
+	return reservations, nil
+}
+
+// SeatedCovers devuelve los comensales sentados ahora mismo en cada
+// restaurante. Solo cuentan los grupos sentados dentro del tiempo de mesa
+// de su restaurante: las reservas que nadie pasó a completada no deben
+// contar para siempre.
+func SeatedCovers(ctx context.Context) (map[string]int, error) {
+	ctx, span := tracing.Start(ctx, "controllers.SeatedCovers")
+	defer span.End()
+
+	restaurants, err := GetRestaurants(ctx)
+	if err != nil {
+		return nil, err
+	}
+	covers := make(map[string]int)
+	if len(restaurants) == 0 {
+		return covers, nil
+	}
+	now := time.Now()
+	current := make(bson.A, 0, len(restaurants))
+	for _, restaurant := range restaurants {
+		current = append(current, bson.M{
+			"restaurantid": restaurant.ID,
+			"seatedat":     bson.M{"$gte": now.Add(-restaurantTurnTime(&restaurant))},
+		})
+	}
+
+	collection := mongoClient.Database("reservations-db").Collection("reservations")
+	cursor, err := collection.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"status":  "sentada",
+			"deleted": notDeleted,
+			"$or":     current,
+		}}},
+		{{Key: "$group", Value: bson.M{"_id": "$restaurantid", "covers": bson.M{"$sum": "$guestcount"}}}},
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to aggregate seated covers", "error", err)
+		return nil, err
+	}
+	var results []struct {
+		RestaurantId string `bson:"_id"`
+		Covers       int    `bson:"covers"`
+	}
+	if err = cursor.All(ctx, &results); err != nil {
+		slog.ErrorContext(ctx, "failed to decode seated covers", "error", err)
+		return nil, err
+	}
+
+	for _, result := range results {
+		covers[result.RestaurantId] = result.Covers
+	}
+	return covers, nil
+}
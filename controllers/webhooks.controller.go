@@ -0,0 +1,492 @@
+package controllers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"strconv"
+	"time"
+
+	"ms-reservas/events"
+	m "ms-reservas/models"
+	pb "ms-reservas/protos_pb/proto"
+	"ms-reservas/tracing"
+	"ms-reservas/webhooks"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Estados de una entrega de webhook
+const (
+	WebhookDeliveryPending   = "pendiente"
+	WebhookDeliveryDelivered = "entregada"
+	WebhookDeliveryFailed    = "fallida"
+)
+
+const (
+	defaultWebhookMaxAttempts  = 8
+	defaultWebhookDeliveryList = 100
+	webhookDispatchBatchSize   = 50
+	// webhookBackoffBase y webhookBackoffMax acotan la espera entre intentos:
+	// 30s, 1m, 2m, 4m... hasta una hora.
+	webhookBackoffBase = 30 * time.Second
+	webhookBackoffMax  = time.Hour
+	// webhookClaimLease es el tiempo que una entrega queda reservada para la
+	// instancia que la envía. Debe superar el timeout del cliente HTTP.
+	webhookClaimLease = time.Minute
+)
+
+// webhookClient es el cliente con el que se envían las entregas y
+// validateWebhookURL la comprobación de las URLs al registrarlas. Ambos
+// rechazan las direcciones internas; las pruebas los sustituyen para
+// entregar a un receptor local.
+var (
+	webhookClient      = webhooks.NewClient(webhooks.DefaultTimeout)
+	validateWebhookURL = webhooks.ValidateURL
+)
+
+// webhookMaxAttempts lee de WEBHOOK_MAX_ATTEMPTS cuántos intentos se hacen
+// antes de dar una entrega por fallida.
+func webhookMaxAttempts() int {
+	attempts, err := strconv.Atoi(os.Getenv("WEBHOOK_MAX_ATTEMPTS"))
+	if err != nil || attempts <= 0 {
+		return defaultWebhookMaxAttempts
+	}
+	return attempts
+}
+
+// EnsureWebhookIndexes crea los índices de las entregas. El índice único por
+// webhook y evento evita entregas duplicadas cuando el relay reintenta.
+func EnsureWebhookIndexes(ctx context.Context) error {
+	collection := mongoClient.Database("reservations-db").Collection("webhook_deliveries")
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "webhookid", Value: 1}, {Key: "eventid", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "nextattemptat", Value: 1}}},
+		{Keys: bson.D{{Key: "webhookid", Value: 1}, {Key: "createat", Value: -1}}},
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to create webhook indexes", "error", err)
+	}
+	return err
+}
+
+// REGISTER
+func RegisterWebhookHandler(ctx context.Context, req *pb.RegisterWebhookRequest) (*pb.Webhook, error) {
+	ctx, span := tracing.Start(ctx, "controllers.RegisterWebhookHandler")
+	defer span.End()
+
+	webhook, err := RegisterWebhook(ctx, req.RestaurantId, req.Url, req.EventTypes, req.Secret)
+	if err != nil {
+		return nil, err
+	}
+	// El secreto solo se devuelve al registrar el webhook.
+	pbWebhook := toPbWebhook(webhook)
+	pbWebhook.Secret = webhook.Secret
+	return pbWebhook, nil
+}
+
+// RegisterWebhook da de alta un webhook. Si no se indica secreto se genera
+// uno aleatorio.
+func RegisterWebhook(ctx context.Context, restaurantID, url string, eventTypes []string, secret string) (m.Webhook, error) {
+	ctx, span := tracing.Start(ctx, "controllers.RegisterWebhook")
+	defer span.End()
+
+	if err := validateWebhookURL(ctx, url); err != nil {
+		return m.Webhook{}, err
+	}
+	for _, eventType := range eventTypes {
+		if !slices.Contains(events.Types, eventType) {
+			return m.Webhook{}, fmt.Errorf("invalid event type %q, expected one of %v", eventType, events.Types)
+		}
+	}
+	if len(eventTypes) == 0 {
+		eventTypes = nil
+	}
+	if restaurantID != "" {
+		if _, err := GetRestaurantByID(ctx, restaurantID); err != nil {
+			return m.Webhook{}, err
+		}
+	}
+	if secret == "" {
+		buf := make([]byte, 32)
+		if _, err := rand.Read(buf); err != nil {
+			return m.Webhook{}, err
+		}
+		secret = hex.EncodeToString(buf)
+	}
+
+	webhook := m.Webhook{
+		RestaurantId: restaurantID,
+		Url:          url,
+		EventTypes:   eventTypes,
+		Secret:       secret,
+		Active:       true,
+		CreatedBy:    actorFromContext(ctx),
+		CreateAt:     time.Now(),
+	}
+	collection := mongoClient.Database("reservations-db").Collection("webhooks")
+	result, err := collection.InsertOne(ctx, webhook)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to insert webhook", "error", err)
+		return m.Webhook{}, err
+	}
+	webhook.ID = result.InsertedID.(primitive.ObjectID).Hex()
+	return webhook, nil
+}
+
+// LIST
+func ListWebhooksHandler(ctx context.Context, req *pb.ListWebhooksRequest) (*pb.Webhooks, error) {
+	ctx, span := tracing.Start(ctx, "controllers.ListWebhooksHandler")
+	defer span.End()
+
+	list, err := ListWebhooks(ctx, req.RestaurantId)
+	if err != nil {
+		return nil, err
+	}
+	var pbWebhooks []*pb.Webhook
+	for _, webhook := range list {
+		pbWebhooks = append(pbWebhooks, toPbWebhook(webhook))
+	}
+	return &pb.Webhooks{Webhooks: pbWebhooks}, nil
+}
+
+// ListWebhooks devuelve los webhooks activos, todos o los de un restaurante.
+func ListWebhooks(ctx context.Context, restaurantID string) ([]m.Webhook, error) {
+	ctx, span := tracing.Start(ctx, "controllers.ListWebhooks")
+	defer span.End()
+
+	filter := bson.M{"active": true}
+	if restaurantID != "" {
+		filter["restaurantid"] = restaurantID
+	}
+	collection := mongoClient.Database("reservations-db").Collection("webhooks")
+	cursor, err := collection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "createat", Value: 1}}))
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to find webhooks", "error", err)
+		return nil, err
+	}
+	var list []m.Webhook
+	if err = cursor.All(ctx, &list); err != nil {
+		slog.ErrorContext(ctx, "failed to decode webhooks", "error", err)
+		return nil, err
+	}
+	return list, nil
+}
+
+// DELETE
+func DeleteWebhookHandler(ctx context.Context, req *pb.WebhookRequest) (*pb.Response, error) {
+	ctx, span := tracing.Start(ctx, "controllers.DeleteWebhookHandler")
+	defer span.End()
+
+	if err := DeleteWebhook(ctx, req.Id); err != nil {
+		return nil, err
+	}
+	return &pb.Response{Message: "Webhook deleted successfully", Success: true}, nil
+}
+
+// DeleteWebhook desactiva el webhook. Se conserva el documento para que su
+// registro de entregas siga siendo consultable.
+func DeleteWebhook(ctx context.Context, id string) error {
+	ctx, span := tracing.Start(ctx, "controllers.DeleteWebhook")
+	defer span.End()
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		slog.WarnContext(ctx, "invalid id format", "error", err)
+		return err
+	}
+	collection := mongoClient.Database("reservations-db").Collection("webhooks")
+	result, err := collection.UpdateOne(ctx, bson.M{"_id": objectID, "active": true}, bson.M{
+		"$set": bson.M{"active": false, "updateat": time.Now()},
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to delete webhook", "error", err)
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("webhook not found")
+	}
+	return nil
+}
+
+// GET DELIVERIES
+func GetWebhookDeliveriesHandler(ctx context.Context, req *pb.GetWebhookDeliveriesRequest) (*pb.WebhookDeliveries, error) {
+	ctx, span := tracing.Start(ctx, "controllers.GetWebhookDeliveriesHandler")
+	defer span.End()
+
+	deliveries, err := GetWebhookDeliveries(ctx, req.WebhookId, req.Status, int64(req.Limit))
+	if err != nil {
+		return nil, err
+	}
+	var pbDeliveries []*pb.WebhookDelivery
+	for _, delivery := range deliveries {
+		pbDeliveries = append(pbDeliveries, toPbWebhookDelivery(delivery))
+	}
+	return &pb.WebhookDeliveries{Deliveries: pbDeliveries}, nil
+}
+
+// GetWebhookDeliveries devuelve las entregas de un webhook, de la más
+// reciente a la más antigua. Con status fallida lista los mensajes muertos.
+func GetWebhookDeliveries(ctx context.Context, webhookID, status string, limit int64) ([]m.WebhookDelivery, error) {
+	ctx, span := tracing.Start(ctx, "controllers.GetWebhookDeliveries")
+	defer span.End()
+
+	if webhookID == "" {
+		return nil, fmt.Errorf("webhookID is required")
+	}
+	filter := bson.M{"webhookid": webhookID}
+	switch status {
+	case "":
+	case WebhookDeliveryPending, WebhookDeliveryDelivered, WebhookDeliveryFailed:
+		filter["status"] = status
+	default:
+		return nil, fmt.Errorf("invalid status, expected %s, %s or %s", WebhookDeliveryPending, WebhookDeliveryDelivered, WebhookDeliveryFailed)
+	}
+	if limit <= 0 {
+		limit = defaultWebhookDeliveryList
+	}
+
+	collection := mongoClient.Database("reservations-db").Collection("webhook_deliveries")
+	opts := options.Find().SetSort(bson.D{{Key: "createat", Value: -1}}).SetLimit(limit)
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to find webhook deliveries", "error", err)
+		return nil, err
+	}
+	var deliveries []m.WebhookDelivery
+	if err = cursor.All(ctx, &deliveries); err != nil {
+		slog.ErrorContext(ctx, "failed to decode webhook deliveries", "error", err)
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// WebhookPublisher reparte cada evento del outbox entre los webhooks
+// suscritos, creando una entrega pendiente por webhook. El envío HTTP lo
+// hace DispatchWebhooks, así un receptor caído no bloquea el relay.
+type WebhookPublisher struct{}
+
+func (WebhookPublisher) Publish(ctx context.Context, event events.Event) error {
+	ctx, span := tracing.Start(ctx, "controllers.WebhookPublisher.Publish")
+	defer span.End()
+
+	collection := mongoClient.Database("reservations-db").Collection("webhooks")
+	cursor, err := collection.Find(ctx, bson.M{
+		"active":       true,
+		"restaurantid": bson.M{"$in": bson.A{"", event.RestaurantID}},
+		"eventtypes":   bson.M{"$in": bson.A{nil, event.Type}},
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to find webhooks", "error", err)
+		return err
+	}
+	var subscribed []m.Webhook
+	if err = cursor.All(ctx, &subscribed); err != nil {
+		slog.ErrorContext(ctx, "failed to decode webhooks", "error", err)
+		return err
+	}
+	if len(subscribed) == 0 {
+		return nil
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	docs := make([]interface{}, 0, len(subscribed))
+	for _, webhook := range subscribed {
+		docs = append(docs, m.WebhookDelivery{
+			WebhookId:     webhook.ID,
+			EventId:       event.ID,
+			EventType:     event.Type,
+			Payload:       string(payload),
+			Status:        WebhookDeliveryPending,
+			NextAttemptAt: now,
+			CreateAt:      now,
+		})
+	}
+	// Si el relay reintenta el evento, las entregas ya creadas chocan con el
+	// índice único y se ignoran.
+	deliveries := mongoClient.Database("reservations-db").Collection("webhook_deliveries")
+	_, err = deliveries.InsertMany(ctx, docs, options.InsertMany().SetOrdered(false))
+	if err != nil && !mongo.IsDuplicateKeyError(err) {
+		slog.ErrorContext(ctx, "failed to insert webhook deliveries", "error", err)
+		return err
+	}
+	return nil
+}
+
+// DispatchWebhooks envía las entregas pendientes cuyo intento toca ya. Cada
+// entrega se reserva atómicamente antes de enviarla, así varias instancias
+// pueden despachar a la vez sin duplicar envíos.
+func DispatchWebhooks(ctx context.Context) {
+	ctx, span := tracing.Start(ctx, "controllers.DispatchWebhooks")
+	defer span.End()
+
+	collection := mongoClient.Database("reservations-db").Collection("webhook_deliveries")
+	maxAttempts := webhookMaxAttempts()
+	cache := map[string]*m.Webhook{}
+
+	for i := 0; i < webhookDispatchBatchSize; i++ {
+		now := time.Now()
+		opts := options.FindOneAndUpdate().
+			SetSort(bson.D{{Key: "nextattemptat", Value: 1}}).
+			SetReturnDocument(options.After)
+		var delivery m.WebhookDelivery
+		err := collection.FindOneAndUpdate(ctx,
+			bson.M{"status": WebhookDeliveryPending, "nextattemptat": bson.M{"$lte": now}},
+			bson.M{"$set": bson.M{"nextattemptat": now.Add(webhookClaimLease)}},
+			opts,
+		).Decode(&delivery)
+		if err == mongo.ErrNoDocuments {
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to claim webhook delivery", "error", err)
+			return
+		}
+
+		webhook, ok := cache[delivery.WebhookId]
+		if !ok {
+			webhook = findWebhook(ctx, delivery.WebhookId)
+			cache[delivery.WebhookId] = webhook
+		}
+		deliverWebhook(ctx, webhook, delivery, maxAttempts)
+	}
+}
+
+// findWebhook devuelve el webhook activo con ese id, o nil si se borró.
+func findWebhook(ctx context.Context, id string) *m.Webhook {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil
+	}
+	collection := mongoClient.Database("reservations-db").Collection("webhooks")
+	var webhook m.Webhook
+	err = collection.FindOne(ctx, bson.M{"_id": objectID, "active": true}).Decode(&webhook)
+	if err != nil {
+		if err != mongo.ErrNoDocuments {
+			slog.ErrorContext(ctx, "failed to find webhook", "webhook_id", id, "error", err)
+		}
+		return nil
+	}
+	return &webhook
+}
+
+// deliverWebhook hace un intento de entrega y guarda el resultado: entregada,
+// pendiente con la espera del backoff o, agotados los intentos, fallida.
+func deliverWebhook(ctx context.Context, webhook *m.Webhook, delivery m.WebhookDelivery, maxAttempts int) {
+	objectID, err := primitive.ObjectIDFromHex(delivery.ID)
+	if err != nil {
+		return
+	}
+	collection := mongoClient.Database("reservations-db").Collection("webhook_deliveries")
+
+	if webhook == nil {
+		_, err = collection.UpdateOne(ctx, bson.M{"_id": objectID}, bson.M{
+			"$set": bson.M{"status": WebhookDeliveryFailed, "lasterror": "webhook deleted"},
+		})
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to update webhook delivery", "delivery_id", delivery.ID, "error", err)
+		}
+		return
+	}
+
+	statusCode, deliverErr := webhooks.Deliver(ctx, webhookClient, webhooks.Request{
+		URL:       webhook.Url,
+		Secret:    webhook.Secret,
+		EventType: delivery.EventType,
+		EventID:   delivery.EventId,
+		Body:      []byte(delivery.Payload),
+	})
+
+	set := deliveryResult(delivery, statusCode, deliverErr, maxAttempts, time.Now())
+	if set["status"] == WebhookDeliveryFailed {
+		slog.WarnContext(ctx, "webhook delivery failed permanently", "webhook_id", webhook.ID, "delivery_id", delivery.ID, "attempts", set["attempts"], "error", deliverErr)
+	}
+	if _, err = collection.UpdateOne(ctx, bson.M{"_id": objectID}, bson.M{"$set": set}); err != nil {
+		slog.ErrorContext(ctx, "failed to update webhook delivery", "delivery_id", delivery.ID, "error", err)
+	}
+}
+
+// deliveryResult calcula los cambios de una entrega tras un intento en now:
+// entregada, pendiente con la espera del backoff o, agotados los intentos,
+// fallida.
+func deliveryResult(delivery m.WebhookDelivery, statusCode int, deliverErr error, maxAttempts int, now time.Time) bson.M {
+	attempts := delivery.Attempts + 1
+	set := bson.M{"attempts": attempts, "laststatuscode": statusCode}
+	switch {
+	case deliverErr == nil:
+		set["status"] = WebhookDeliveryDelivered
+		set["deliveredat"] = now
+		set["lasterror"] = ""
+	case attempts >= maxAttempts:
+		set["status"] = WebhookDeliveryFailed
+		set["lasterror"] = deliverErr.Error()
+	default:
+		set["nextattemptat"] = now.Add(webhooks.Backoff(attempts, webhookBackoffBase, webhookBackoffMax))
+		set["lasterror"] = deliverErr.Error()
+	}
+	return set
+}
+
+// StartWebhookDispatcher envía periódicamente las entregas pendientes hasta
+// que se cancele ctx.
+func StartWebhookDispatcher(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				DispatchWebhooks(ctx)
+			}
+		}
+	}()
+}
+
+func toPbWebhook(webhook m.Webhook) *pb.Webhook {
+	return &pb.Webhook{
+		Id:           webhook.ID,
+		RestaurantId: webhook.RestaurantId,
+		Url:          webhook.Url,
+		EventTypes:   webhook.EventTypes,
+		Active:       webhook.Active,
+		CreatedAt:    webhook.CreateAt.Format(time.RFC3339),
+	}
+}
+
+func toPbWebhookDelivery(delivery m.WebhookDelivery) *pb.WebhookDelivery {
+	pbDelivery := &pb.WebhookDelivery{
+		Id:             delivery.ID,
+		WebhookId:      delivery.WebhookId,
+		EventId:        delivery.EventId,
+		EventType:      delivery.EventType,
+		Status:         delivery.Status,
+		Attempts:       int32(delivery.Attempts),
+		LastStatusCode: int32(delivery.LastStatusCode),
+		LastError:      delivery.LastError,
+		CreatedAt:      delivery.CreateAt.Format(time.RFC3339),
+	}
+	if delivery.Status == WebhookDeliveryPending {
+		pbDelivery.NextAttemptAt = delivery.NextAttemptAt.Format(time.RFC3339)
+	}
+	if !delivery.DeliveredAt.IsZero() {
+		pbDelivery.DeliveredAt = delivery.DeliveredAt.Format(time.RFC3339)
+	}
+	return pbDelivery
+}
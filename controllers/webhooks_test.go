@@ -0,0 +1,122 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	m "ms-reservas/models"
+	"ms-reservas/webhooks"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestDeliveryResult(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	failure := errors.New("webhook endpoint responded 503")
+
+	set := deliveryResult(m.WebhookDelivery{}, http.StatusOK, nil, 3, now)
+	if set["status"] != WebhookDeliveryDelivered || set["deliveredat"] != now || set["attempts"] != 1 {
+		t.Errorf("successful delivery = %v, want delivered on the first attempt", set)
+	}
+
+	set = deliveryResult(m.WebhookDelivery{Attempts: 1}, http.StatusServiceUnavailable, failure, 3, now)
+	if _, ok := set["status"]; ok {
+		t.Errorf("retried delivery should stay pending, got status %v", set["status"])
+	}
+	if want := now.Add(webhooks.Backoff(2, webhookBackoffBase, webhookBackoffMax)); set["nextattemptat"] != want {
+		t.Errorf("next attempt = %v, want %v", set["nextattemptat"], want)
+	}
+
+	set = deliveryResult(m.WebhookDelivery{Attempts: 2}, http.StatusServiceUnavailable, failure, 3, now)
+	if set["status"] != WebhookDeliveryFailed || set["lasterror"] != failure.Error() {
+		t.Errorf("last attempt = %v, want a failed delivery with the error", set)
+	}
+}
+
+// useTestReceiver hace que las entregas y los registros acepten el
+// receptor local de la prueba.
+func useTestReceiver(t *testing.T, server *httptest.Server) {
+	t.Helper()
+	client, validate := webhookClient, validateWebhookURL
+	webhookClient = server.Client()
+	validateWebhookURL = func(context.Context, string) error { return nil }
+	t.Cleanup(func() { webhookClient, validateWebhookURL = client, validate })
+}
+
+func TestDispatchWebhooks(t *testing.T) {
+	ctx := connectTestMongo(t)
+	t.Setenv("WEBHOOK_MAX_ATTEMPTS", "2")
+
+	var status atomic.Int32
+	status.Store(http.StatusOK)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(int(status.Load()))
+	}))
+	defer server.Close()
+	useTestReceiver(t, server)
+
+	webhook, err := RegisterWebhook(ctx, "", server.URL, nil, "secret")
+	if err != nil {
+		t.Fatalf("RegisterWebhook() error = %v", err)
+	}
+	deliveries := mongoClient.Database("reservations-db").Collection("webhook_deliveries")
+	t.Cleanup(func() {
+		objectID, _ := primitive.ObjectIDFromHex(webhook.ID)
+		mongoClient.Database("reservations-db").Collection("webhooks").DeleteOne(context.Background(), bson.M{"_id": objectID})
+		deliveries.DeleteMany(context.Background(), bson.M{"webhookid": webhook.ID})
+	})
+
+	enqueue := func(eventID string) primitive.ObjectID {
+		result, err := deliveries.InsertOne(ctx, m.WebhookDelivery{
+			WebhookId:     webhook.ID,
+			EventId:       eventID,
+			EventType:     "ReservationCreated",
+			Payload:       `{}`,
+			Status:        WebhookDeliveryPending,
+			NextAttemptAt: time.Now().Add(-time.Second),
+			CreateAt:      time.Now(),
+		})
+		if err != nil {
+			t.Fatalf("failed to insert delivery: %v", err)
+		}
+		return result.InsertedID.(primitive.ObjectID)
+	}
+	find := func(id primitive.ObjectID) m.WebhookDelivery {
+		var delivery m.WebhookDelivery
+		if err := deliveries.FindOne(ctx, bson.M{"_id": id}).Decode(&delivery); err != nil {
+			t.Fatalf("failed to find delivery: %v", err)
+		}
+		return delivery
+	}
+
+	delivered := enqueue(primitive.NewObjectID().Hex())
+	DispatchWebhooks(ctx)
+	if got := find(delivered); got.Status != WebhookDeliveryDelivered || got.Attempts != 1 {
+		t.Fatalf("delivery = %+v, want delivered after one attempt", got)
+	}
+
+	status.Store(http.StatusServiceUnavailable)
+	retried := enqueue(primitive.NewObjectID().Hex())
+	before := time.Now()
+	DispatchWebhooks(ctx)
+	got := find(retried)
+	if got.Status != WebhookDeliveryPending || got.Attempts != 1 || got.LastStatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("delivery = %+v, want pending after a failed attempt", got)
+	}
+	if !got.NextAttemptAt.After(before.Add(webhookBackoffBase - time.Second)) {
+		t.Errorf("next attempt at %v, want it pushed back by the backoff", got.NextAttemptAt)
+	}
+
+	// Vencida la espera, el segundo fallo agota los intentos
+	deliveries.UpdateOne(ctx, bson.M{"_id": retried}, bson.M{"$set": bson.M{"nextattemptat": time.Now().Add(-time.Second)}})
+	DispatchWebhooks(ctx)
+	if got := find(retried); got.Status != WebhookDeliveryFailed || got.Attempts != 2 {
+		t.Fatalf("delivery = %+v, want failed after the maximum attempts", got)
+	}
+}
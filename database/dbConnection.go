@@ -2,10 +2,14 @@ package database
 
 import (
 	"context"
-	"log"
+	"log/slog"
 	"os"
 
+	"ms-reservas/metrics"
+	"ms-reservas/tracing"
+
 	"github.com/joho/godotenv"
+	"go.mongodb.org/mongo-driver/event"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
@@ -13,16 +17,18 @@ import (
 func ConnectMongoDB() *mongo.Client {
 	err := godotenv.Load()
 	if err != nil {
-		log.Fatalf("Error loading .env file")
+		slog.Error("error loading .env file", "error", err)
+		os.Exit(1)
 	}
 
 	uri := os.Getenv("MONGODB_URI")
 	if uri == "" {
-		log.Fatal("Set your 'MONGODB_URI' environment variable.")
+		slog.Error("set your 'MONGODB_URI' environment variable")
+		os.Exit(1)
 	}
 
 	serverAPI := options.ServerAPI(options.ServerAPIVersion1)
-	opts := options.Client().ApplyURI(uri).SetServerAPIOptions(serverAPI)
+	opts := options.Client().ApplyURI(uri).SetServerAPIOptions(serverAPI).SetMonitor(combineMonitors(metrics.CommandMonitor(), tracing.CommandMonitor()))
 
 	client, err := mongo.Connect(context.TODO(), opts)
 	if err != nil {
@@ -31,10 +37,39 @@ func ConnectMongoDB() *mongo.Client {
 
 	err = client.Ping(context.TODO(), nil)
 	if err != nil {
-		log.Fatalf("Failed to ping MongoDB: %v", err)
+		slog.Error("failed to ping MongoDB", "error", err)
+		os.Exit(1)
 	}
 
-	log.Println("Connected to MongoDB")
+	slog.Info("connected to MongoDB")
 
 	return client
 }
+
+// combineMonitors reparte los eventos de comandos entre varios monitores, ya
+// que el driver solo admite uno.
+func combineMonitors(monitors ...*event.CommandMonitor) *event.CommandMonitor {
+	return &event.CommandMonitor{
+		Started: func(ctx context.Context, e *event.CommandStartedEvent) {
+			for _, m := range monitors {
+				if m.Started != nil {
+					m.Started(ctx, e)
+				}
+			}
+		},
+		Succeeded: func(ctx context.Context, e *event.CommandSucceededEvent) {
+			for _, m := range monitors {
+				if m.Succeeded != nil {
+					m.Succeeded(ctx, e)
+				}
+			}
+		},
+		Failed: func(ctx context.Context, e *event.CommandFailedEvent) {
+			for _, m := range monitors {
+				if m.Failed != nil {
+					m.Failed(ctx, e)
+				}
+			}
+		},
+	}
+}
@@ -0,0 +1,98 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	m "ms-reservas/models"
+)
+
+// Tipos de evento de dominio
+const (
+	ReservationCreated      = "ReservationCreated"
+	ReservationUpdated      = "ReservationUpdated"
+	ReservationCancelled    = "ReservationCancelled"
+	ReservationSeated       = "ReservationSeated"
+	ReservationNoShow       = "ReservationNoShow"
+	ReservationTableChanged = "ReservationTableChanged"
+	ReservationReminder     = "ReservationReminder"
+)
+
+// Types lista todos los tipos de evento que se publican.
+var Types = []string{
+	ReservationCreated,
+	ReservationUpdated,
+	ReservationCancelled,
+	ReservationSeated,
+	ReservationNoShow,
+	ReservationTableChanged,
+	ReservationReminder,
+}
+
+// Event es un evento de dominio tal como se publica hacia otros servicios.
+// ID es estable entre reintentos, para que los consumidores puedan
+// descartar duplicados: la entrega es al menos una vez.
+type Event struct {
+	ID           string          `json:"id"`
+	Type         string          `json:"type"`
+	AggregateID  string          `json:"aggregate_id"`
+	RestaurantID string          `json:"restaurant_id"`
+	OccurredAt   time.Time       `json:"occurred_at"`
+	Payload      json.RawMessage `json:"payload"`
+}
+
+// ReservationPayload es el contenido de los eventos de reservas: el estado
+// de la reserva tras el cambio y los campos modificados.
+type ReservationPayload struct {
+	Reservation m.Reservation   `json:"reservation"`
+	Changes     []m.FieldChange `json:"changes,omitempty"`
+}
+
+// Publisher entrega eventos a sus consumidores. Publish debe devolver error
+// si el evento no se entregó, para que se reintente.
+type Publisher interface {
+	Publish(ctx context.Context, event Event) error
+}
+
+// PublisherFromEnv crea el publicador indicado en EVENTS_PUBLISHER:
+//   - stdout: escribe cada evento como una línea JSON en la salida estándar.
+//   - file: añade las líneas JSON al fichero EVENTS_FILE.
+//   - none o vacío: no hay publicador y los eventos quedan en el outbox.
+func PublisherFromEnv() (Publisher, error) {
+	switch name := strings.ToLower(os.Getenv("EVENTS_PUBLISHER")); name {
+	case "", "none":
+		return nil, nil
+	case "stdout":
+		return NewWriterPublisher(os.Stdout), nil
+	case "file":
+		path := os.Getenv("EVENTS_FILE")
+		if path == "" {
+			return nil, fmt.Errorf("EVENTS_FILE is required when EVENTS_PUBLISHER=file")
+		}
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open events file: %w", err)
+		}
+		return NewWriterPublisher(file), nil
+	default:
+		return nil, fmt.Errorf("invalid EVENTS_PUBLISHER %q, expected stdout, file or none", name)
+	}
+}
+
+// MultiPublisher entrega cada evento a varios publicadores, en orden. Si uno
+// falla se devuelve su error sin seguir, y el relay reintentará el evento
+// completo: los publicadores deben tolerar duplicados.
+type MultiPublisher []Publisher
+
+func (p MultiPublisher) Publish(ctx context.Context, event Event) error {
+	for _, publisher := range p {
+		if err := publisher.Publish(ctx, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type failingPublisher struct{}
+
+func (failingPublisher) Publish(context.Context, Event) error {
+	return errors.New("broker unavailable")
+}
+
+func TestWriterPublisher(t *testing.T) {
+	var buf bytes.Buffer
+	publisher := NewWriterPublisher(&buf)
+	for _, id := range []string{"1", "2"} {
+		if err := publisher.Publish(context.Background(), Event{ID: id, Type: ReservationCreated}); err != nil {
+			t.Fatalf("Publish() error = %v", err)
+		}
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2", len(lines))
+	}
+	var event Event
+	if err := json.Unmarshal([]byte(lines[1]), &event); err != nil {
+		t.Fatalf("invalid JSON line: %v", err)
+	}
+	if event.ID != "2" || event.Type != ReservationCreated {
+		t.Errorf("event = %+v, want ID 2 of type %s", event, ReservationCreated)
+	}
+}
+
+func TestMultiPublisher(t *testing.T) {
+	first, last := NewMemoryPublisher(), NewMemoryPublisher()
+
+	err := MultiPublisher{first, last}.Publish(context.Background(), Event{ID: "1"})
+	if err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	if len(first.Events()) != 1 || len(last.Events()) != 1 {
+		t.Fatalf("every publisher should receive the event")
+	}
+
+	err = MultiPublisher{first, failingPublisher{}, last}.Publish(context.Background(), Event{ID: "2"})
+	if err == nil {
+		t.Fatal("expected the failing publisher's error")
+	}
+	if len(last.Events()) != 1 {
+		t.Errorf("publishers after a failure should not receive the event")
+	}
+}
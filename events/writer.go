@@ -0,0 +1,30 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+)
+
+// WriterPublisher escribe cada evento como una línea JSON en w. Sirve para
+// depurar en local o para que otro proceso siga el fichero.
+type WriterPublisher struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func NewWriterPublisher(w io.Writer) *WriterPublisher {
+	return &WriterPublisher{w: w}
+}
+
+func (p *WriterPublisher) Publish(_ context.Context, event Event) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, err = p.w.Write(append(line, '\n'))
+	return err
+}
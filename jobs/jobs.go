@@ -0,0 +1,266 @@
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	m "ms-reservas/models"
+	"ms-reservas/tracing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Estados de un job
+const (
+	StatusPending   = "pendiente"
+	StatusRunning   = "en_curso"
+	StatusCompleted = "completada"
+	StatusFailed    = "fallida"
+)
+
+const (
+	defaultLease       = 5 * time.Minute
+	defaultMaxAttempts = 5
+	defaultRetryDelay  = time.Minute
+	batchSize          = 100
+	// completedRetention es cuánto se conservan los jobs completados antes
+	// de que Mongo los borre. Los fallidos se conservan para revisarlos.
+	completedRetention = 7 * 24 * time.Hour
+)
+
+// Handler ejecuta un job. Si devuelve error el job se reintenta más tarde,
+// salvo que sea el de RunAgainAt, que lo reprograma sin contar como fallo.
+type Handler func(ctx context.Context, job m.Job) error
+
+type runAgainError struct {
+	at time.Time
+}
+
+func (e *runAgainError) Error() string {
+	return fmt.Sprintf("run again at %s", e.at.Format(time.RFC3339))
+}
+
+// RunAgainAt pide volver a ejecutar el job en at, p. ej. porque todavía no
+// es el momento de actuar.
+func RunAgainAt(at time.Time) error {
+	return &runAgainError{at: at}
+}
+
+// Runner ejecuta los jobs guardados en una colección de Mongo. Cada job se
+// reserva atómicamente durante Lease antes de ejecutarse, así varias
+// réplicas pueden compartir la colección sin ejecutarlo dos veces; si una
+// réplica cae con el job reservado, otra lo retoma al vencer la reserva.
+type Runner struct {
+	collection  *mongo.Collection
+	owner       string
+	handlers    map[string]Handler
+	Lease       time.Duration
+	MaxAttempts int
+	RetryDelay  time.Duration
+}
+
+func NewRunner(collection *mongo.Collection) *Runner {
+	return &Runner{
+		collection:  collection,
+		owner:       runnerID(),
+		handlers:    map[string]Handler{},
+		Lease:       defaultLease,
+		MaxAttempts: defaultMaxAttempts,
+		RetryDelay:  defaultRetryDelay,
+	}
+}
+
+// runnerID identifica a esta réplica en los jobs que tiene reservados.
+func runnerID() string {
+	host, _ := os.Hostname()
+	buf := make([]byte, 4)
+	rand.Read(buf)
+	return fmt.Sprintf("%s-%d-%s", host, os.Getpid(), hex.EncodeToString(buf))
+}
+
+// Handle registra el handler de un tipo de job. Debe llamarse antes de
+// Start.
+func (r *Runner) Handle(jobType string, handler Handler) {
+	r.handlers[jobType] = handler
+}
+
+// EnsureIndexes crea el índice único por clave, el usado para buscar los
+// jobs pendientes y el TTL que purga los completados.
+func (r *Runner) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "key", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "runat", Value: 1}}},
+		{Keys: bson.D{{Key: "completedat", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(int32(completedRetention.Seconds()))},
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to create job indexes", "error", err)
+	}
+	return err
+}
+
+// Schedule programa el job key para runAt. Si ya existía se reprograma con
+// los nuevos datos, aunque ya se hubiera ejecutado.
+func (r *Runner) Schedule(ctx context.Context, jobType, key string, runAt time.Time, data map[string]string) error {
+	now := time.Now()
+	_, err := r.collection.UpdateOne(ctx, bson.M{"key": key}, bson.M{
+		"$set": bson.M{
+			"type":        jobType,
+			"data":        data,
+			"status":      StatusPending,
+			"runat":       runAt,
+			"attempts":    0,
+			"lockedby":    "",
+			"lockeduntil": time.Time{},
+			"lasterror":   "",
+			"updateat":    now,
+		},
+		// Un job reprogramado deja de estar completado y no debe purgarse
+		"$unset":       bson.M{"completedat": ""},
+		"$setOnInsert": bson.M{"createat": now},
+	}, options.Update().SetUpsert(true))
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to schedule job", "type", jobType, "key", key, "error", err)
+	}
+	return err
+}
+
+// Enqueue programa el job key para runAt solo si no existía, de modo que
+// repetir la llamada (p. ej. al reintentar un evento) no lo vuelve a
+// ejecutar.
+func (r *Runner) Enqueue(ctx context.Context, jobType, key string, runAt time.Time, data map[string]string) error {
+	now := time.Now()
+	_, err := r.collection.UpdateOne(ctx, bson.M{"key": key}, bson.M{
+		"$setOnInsert": bson.M{
+			"type":     jobType,
+			"data":     data,
+			"status":   StatusPending,
+			"runat":    runAt,
+			"attempts": 0,
+			"createat": now,
+			"updateat": now,
+		},
+	}, options.Update().SetUpsert(true))
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to enqueue job", "type", jobType, "key", key, "error", err)
+	}
+	return err
+}
+
+// Cancel elimina el job key si todavía no se ha ejecutado.
+func (r *Runner) Cancel(ctx context.Context, key string) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"key": key, "status": StatusPending})
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to cancel job", "key", key, "error", err)
+	}
+	return err
+}
+
+// RunDue ejecuta los jobs vencidos, hasta un lote por llamada, y devuelve
+// cuántos ha ejecutado.
+func (r *Runner) RunDue(ctx context.Context) int {
+	ran := 0
+	for ran < batchSize {
+		job, err := r.claim(ctx)
+		if err == mongo.ErrNoDocuments {
+			break
+		}
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to claim job", "error", err)
+			break
+		}
+		r.run(ctx, job)
+		ran++
+	}
+	return ran
+}
+
+// claim reserva el siguiente job vencido: uno pendiente o uno en curso cuya
+// reserva ha caducado.
+func (r *Runner) claim(ctx context.Context) (m.Job, error) {
+	types := make(bson.A, 0, len(r.handlers))
+	for jobType := range r.handlers {
+		types = append(types, jobType)
+	}
+	now := time.Now()
+	filter := bson.M{
+		"type": bson.M{"$in": types},
+		"$or": bson.A{
+			bson.M{"status": StatusPending, "runat": bson.M{"$lte": now}},
+			bson.M{"status": StatusRunning, "lockeduntil": bson.M{"$lte": now}},
+		},
+	}
+	update := bson.M{
+		"$set": bson.M{"status": StatusRunning, "lockedby": r.owner, "lockeduntil": now.Add(r.Lease), "updateat": now},
+		"$inc": bson.M{"attempts": 1},
+	}
+	opts := options.FindOneAndUpdate().SetSort(bson.D{{Key: "runat", Value: 1}}).SetReturnDocument(options.After)
+	var job m.Job
+	err := r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&job)
+	return job, err
+}
+
+func (r *Runner) run(ctx context.Context, job m.Job) {
+	ctx, span := tracing.Start(ctx, "jobs."+job.Type)
+	defer span.End()
+
+	err := r.handlers[job.Type](ctx, job)
+
+	now := time.Now()
+	set := bson.M{"lockedby": "", "lockeduntil": time.Time{}, "updateat": now}
+	var again *runAgainError
+	switch {
+	case err == nil:
+		set["status"] = StatusCompleted
+		set["completedat"] = now
+		set["lasterror"] = ""
+	case errors.As(err, &again):
+		set["status"] = StatusPending
+		set["runat"] = again.at
+		set["attempts"] = 0
+	case job.Attempts >= r.MaxAttempts:
+		slog.ErrorContext(ctx, "job failed permanently", "type", job.Type, "key", job.Key, "attempts", job.Attempts, "error", err)
+		set["status"] = StatusFailed
+		set["lasterror"] = err.Error()
+	default:
+		slog.WarnContext(ctx, "job failed", "type", job.Type, "key", job.Key, "attempts", job.Attempts, "error", err)
+		set["status"] = StatusPending
+		set["runat"] = now.Add(r.RetryDelay * time.Duration(job.Attempts))
+		set["lasterror"] = err.Error()
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(job.ID)
+	if err != nil {
+		return
+	}
+	// Solo se actualiza si la reserva sigue siendo nuestra y nadie ha
+	// reprogramado el job mientras se ejecutaba.
+	_, err = r.collection.UpdateOne(ctx, bson.M{"_id": objectID, "lockedby": r.owner, "status": StatusRunning}, bson.M{"$set": set})
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to update job", "type", job.Type, "key", job.Key, "error", err)
+	}
+}
+
+// Start ejecuta periódicamente los jobs vencidos hasta que se cancele ctx.
+func (r *Runner) Start(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				r.RunDue(ctx)
+			}
+		}
+	}()
+}
@@ -0,0 +1,72 @@
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+)
+
+// RequestIDHeader es la cabecera de metadatos con la que se recibe y se
+// devuelve el identificador de la petición.
+const RequestIDHeader = "x-request-id"
+
+// requestInfo se comparte por puntero para que los interceptores que corren
+// después del de logging (p. ej. autenticación) puedan completar el llamante.
+type requestInfo struct {
+	id string
+
+	mu     sync.Mutex
+	caller string
+}
+
+type requestKey struct{}
+
+// NewContext devuelve una copia de ctx asociada al identificador de petición.
+func NewContext(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestKey{}, &requestInfo{id: requestID})
+}
+
+func infoFromContext(ctx context.Context) *requestInfo {
+	if ctx == nil {
+		return nil
+	}
+	info, _ := ctx.Value(requestKey{}).(*requestInfo)
+	return info
+}
+
+// RequestID devuelve el identificador de la petición en curso, o "" si no hay.
+func RequestID(ctx context.Context) string {
+	if info := infoFromContext(ctx); info != nil {
+		return info.id
+	}
+	return ""
+}
+
+// SetCaller registra el llamante autenticado de la petición en curso.
+func SetCaller(ctx context.Context, caller string) {
+	if info := infoFromContext(ctx); info != nil {
+		info.mu.Lock()
+		info.caller = caller
+		info.mu.Unlock()
+	}
+}
+
+// Caller devuelve el llamante registrado con SetCaller, o "" si no hay.
+func Caller(ctx context.Context) string {
+	if info := infoFromContext(ctx); info != nil {
+		info.mu.Lock()
+		defer info.mu.Unlock()
+		return info.caller
+	}
+	return ""
+}
+
+// NewRequestID genera un identificador de petición aleatorio.
+func NewRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,75 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Setup configura el logger por defecto de slog a partir de LOG_LEVEL
+// (debug, info, warn, error; info por defecto) y LOG_FORMAT (json o text;
+// json por defecto). Los mensajes del paquete log también pasan por él.
+func Setup() error {
+	handler, err := newHandlerFromEnv(os.Stdout)
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(slog.New(handler))
+	return nil
+}
+
+func newHandlerFromEnv(w io.Writer) (slog.Handler, error) {
+	var level slog.Level
+	if value := os.Getenv("LOG_LEVEL"); value != "" {
+		if err := level.UnmarshalText([]byte(value)); err != nil {
+			return nil, fmt.Errorf("invalid LOG_LEVEL %q", value)
+		}
+	}
+	opts := &slog.HandlerOptions{Level: level}
+
+	switch format := strings.ToLower(os.Getenv("LOG_FORMAT")); format {
+	case "", "json":
+		return NewHandler(slog.NewJSONHandler(w, opts)), nil
+	case "text":
+		return NewHandler(slog.NewTextHandler(w, opts)), nil
+	default:
+		return nil, fmt.Errorf("invalid LOG_FORMAT %q, expected json or text", format)
+	}
+}
+
+// Handler añade a cada registro el identificador de petición, el llamante y
+// la traza activa guardados en el contexto.
+type Handler struct {
+	slog.Handler
+}
+
+// NewHandler envuelve h para que incluya los datos de la petición.
+func NewHandler(h slog.Handler) *Handler {
+	return &Handler{Handler: h}
+}
+
+func (h *Handler) Handle(ctx context.Context, record slog.Record) error {
+	if id := RequestID(ctx); id != "" {
+		record.AddAttrs(slog.String("request_id", id))
+	}
+	if caller := Caller(ctx); caller != "" {
+		record.AddAttrs(slog.String("caller", caller))
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		record.AddAttrs(slog.String("trace_id", sc.TraceID().String()), slog.String("span_id", sc.SpanID().String()))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &Handler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *Handler) WithGroup(name string) slog.Handler {
+	return &Handler{Handler: h.Handler.WithGroup(name)}
+}
@@ -1,32 +1,189 @@
 package main
 
 import (
-	"log"
+	"context"
+	"fmt"
+	"log/slog"
 	"net"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+	// La imagen alpine no trae zonas horarias y cada restaurante define la suya
+	_ "time/tzdata"
 
+	"ms-reservas/auth"
 	"ms-reservas/controllers"
 	"ms-reservas/database"
+	"ms-reservas/events"
+	"ms-reservas/logging"
+	"ms-reservas/metrics"
+	"ms-reservas/notifications"
+	"ms-reservas/payments"
 	pb "ms-reservas/protos_pb/proto"
 	"ms-reservas/server"
+	"ms-reservas/tracing"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 )
 
 func main() {
+	if err := logging.Setup(); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to configure logging: %v\n", err)
+		os.Exit(1)
+	}
+
+	shutdownTracing, err := tracing.Setup(context.Background())
+	if err != nil {
+		fatal("failed to configure tracing", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			slog.Error("failed to flush traces", "error", err)
+		}
+	}()
+
 	client := database.ConnectMongoDB()
 	controllers.SetMongoClient(client)
+	if err := controllers.EnsureReservationIndexes(context.Background()); err != nil {
+		fatal("failed to create reservation indexes", err)
+	}
+	if err := controllers.EnsureHoldIndexes(context.Background()); err != nil {
+		fatal("failed to create hold indexes", err)
+	}
+	if err := controllers.EnsureAuditIndexes(context.Background()); err != nil {
+		fatal("failed to create audit indexes", err)
+	}
+	if err := controllers.EnsureOutboxIndexes(context.Background()); err != nil {
+		fatal("failed to create outbox indexes", err)
+	}
+	if err := controllers.EnsureWebhookIndexes(context.Background()); err != nil {
+		fatal("failed to create webhook indexes", err)
+	}
+	if err := controllers.EnsureJobIndexes(context.Background()); err != nil {
+		fatal("failed to create job indexes", err)
+	}
+	if err := controllers.EnsureReliabilityIndexes(context.Background()); err != nil {
+		fatal("failed to create reliability indexes", err)
+	}
+	if err := controllers.EnsureGuestIndexes(context.Background()); err != nil {
+		fatal("failed to create guest indexes", err)
+	}
+	controllers.StartWaitlistSweeper(context.Background(), time.Minute)
+	controllers.StartReservationPurger(context.Background(), time.Hour)
+	controllers.StartJobRunner(context.Background(), 30*time.Second)
+
+	publisher, err := events.PublisherFromEnv()
+	if err != nil {
+		fatal("failed to configure events publisher", err)
+	}
+	// Los webhooks reciben siempre los eventos; EVENTS_PUBLISHER añade un
+	// destino más.
+	publishers := events.MultiPublisher{controllers.WebhookPublisher{}}
+	if publisher != nil {
+		publishers = append(publishers, publisher)
+	}
+	channels, err := notifications.ChannelsFromEnv()
+	if err != nil {
+		fatal("failed to configure notification channels", err)
+	}
+	if len(channels) > 0 {
+		controllers.SetNotifier(&notifications.Notifier{
+			Channels:       channels,
+			RestaurantName: controllers.RestaurantName,
+		})
+		publishers = append(publishers, controllers.NotificationPublisher{})
+	}
+	controllers.StartOutboxRelay(context.Background(), publishers, 5*time.Second)
+	controllers.StartWebhookDispatcher(context.Background(), 5*time.Second)
+
+	paymentProvider, err := payments.ProviderFromEnv()
+	if err != nil {
+		fatal("failed to configure payment provider", err)
+	}
+	controllers.SetPaymentProvider(paymentProvider)
+
+	metrics.RegisterSeatedCovers(controllers.SeatedCovers)
+	metrics.Serve()
 
 	lis, err := net.Listen("tcp", ":9000")
 	if err != nil {
-		log.Fatalf("Failed to listen: %v", err)
+		fatal("failed to listen", err)
+	}
+
+	verifier, err := auth.LoadVerifierFromEnv()
+	if err != nil {
+		fatal("failed to load JWT keys", err)
+	}
+
+	policy, err := auth.LoadPolicy()
+	if err != nil {
+		fatal("failed to load authorization policy", err)
+	}
+
+	var opts []grpc.ServerOption
+
+	tlsConfig, err := server.TLSConfigFromEnv()
+	if err != nil {
+		fatal("failed to configure TLS", err)
+	}
+	if tlsConfig != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	} else {
+		slog.Warn("TLS disabled: set TLS_CERT_FILE and TLS_KEY_FILE to enable it")
+	}
+
+	unary := []grpc.UnaryServerInterceptor{tracing.UnaryServerInterceptor(), server.LoggingUnaryInterceptor(), metrics.UnaryServerInterceptor()}
+	stream := []grpc.StreamServerInterceptor{tracing.StreamServerInterceptor(), server.LoggingStreamInterceptor(), metrics.StreamServerInterceptor()}
+	if verifier != nil {
+		unary = append(unary, server.AuthUnaryInterceptor(verifier, policy), server.AuthorizationUnaryInterceptor(policy))
+		stream = append(stream, server.AuthStreamInterceptor(verifier, policy), server.AuthorizationStreamInterceptor(policy))
+	} else if os.Getenv("AUTH_DISABLED") == "true" {
+		slog.Warn("authentication disabled: AUTH_DISABLED=true")
+	} else {
+		slog.Error("set JWT_HS256_SECRET or JWT_JWKS_FILE, or AUTH_DISABLED=true for local development")
+		os.Exit(1)
 	}
 
-	s := grpc.NewServer()
+	rateLimits, err := server.LoadRateLimitConfig()
+	if err != nil {
+		fatal("failed to load rate limits", err)
+	}
+	limiter := server.NewRateLimiter(rateLimits)
+	unary = append(unary, limiter.UnaryInterceptor())
+	stream = append(stream, limiter.StreamInterceptor())
+
+	opts = append(opts, grpc.ChainUnaryInterceptor(unary...), grpc.ChainStreamInterceptor(stream...))
+
+	s := grpc.NewServer(opts...)
 	pb.RegisterReservationServiceServer(s, &server.Server{})
 	pb.RegisterTableServiceServer(s, &server.Server{})
+	pb.RegisterWaitlistServiceServer(s, &server.Server{})
+	pb.RegisterRestaurantServiceServer(s, &server.Server{})
+	pb.RegisterAuditServiceServer(s, &server.Server{})
+	pb.RegisterWebhookServiceServer(s, &server.Server{})
+	pb.RegisterGuestServiceServer(s, &server.Server{})
+
+	// Al recibir SIGINT/SIGTERM se terminan las llamadas en curso antes de
+	// salir, para no perder los últimos spans.
+	go func() {
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+		<-signals
+		slog.Info("shutting down gRPC server")
+		s.GracefulStop()
+	}()
 
-	log.Printf("gRPC server listening at %v", lis.Addr())
+	slog.Info("gRPC server listening", "addr", lis.Addr().String())
 	if err := s.Serve(lis); err != nil {
-		log.Fatalf("Failed to serve: %v", err)
+		slog.Error("failed to serve", "error", err)
 	}
 }
+
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
+}
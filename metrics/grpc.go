@@ -0,0 +1,68 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	grpcStarted = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "grpc_server_started_total",
+		Help: "Llamadas gRPC recibidas, por método.",
+	}, []string{"grpc_method"})
+
+	grpcHandled = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "grpc_server_handled_total",
+		Help: "Llamadas gRPC finalizadas, por método y código de estado.",
+	}, []string{"grpc_method", "grpc_code"})
+
+	grpcHandlingSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "grpc_server_handling_seconds",
+		Help:    "Latencia de las llamadas gRPC, por método.",
+		Buckets: []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+	}, []string{"grpc_method"})
+
+	grpcInFlight = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "grpc_server_in_flight",
+		Help: "Llamadas gRPC en curso, por método.",
+	}, []string{"grpc_method"})
+)
+
+func init() {
+	Registry.MustRegister(grpcStarted, grpcHandled, grpcHandlingSeconds, grpcInFlight)
+}
+
+// UnaryServerInterceptor mide cada llamada unaria.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		done := observeCall(info.FullMethod)
+		resp, err := handler(ctx, req)
+		done(err)
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor mide cada llamada en streaming.
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		done := observeCall(info.FullMethod)
+		err := handler(srv, ss)
+		done(err)
+		return err
+	}
+}
+
+func observeCall(method string) func(err error) {
+	start := time.Now()
+	grpcStarted.WithLabelValues(method).Inc()
+	grpcInFlight.WithLabelValues(method).Inc()
+	return func(err error) {
+		grpcInFlight.WithLabelValues(method).Dec()
+		grpcHandled.WithLabelValues(method, status.Code(err).String()).Inc()
+		grpcHandlingSeconds.WithLabelValues(method).Observe(time.Since(start).Seconds())
+	}
+}
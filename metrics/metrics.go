@@ -0,0 +1,138 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// DefaultAddr es la dirección del endpoint /metrics si no se define
+// METRICS_ADDR.
+const DefaultAddr = ":9090"
+
+// Registry agrupa todas las métricas del servicio. No se usa el registro
+// global de Prometheus para no exponer métricas registradas por dependencias.
+var Registry = prometheus.NewRegistry()
+
+var (
+	reservationsCreated = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "reservations_created_total",
+		Help: "Reservas creadas, por estado inicial y origen.",
+	}, []string{"status", "source"})
+
+	reservationStatusChanges = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "reservations_status_changes_total",
+		Help: "Cambios de estado de reservas, por estado de destino (cancelada, sentada, completada...).",
+	}, []string{"status"})
+
+	availabilityQueryDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "availability_query_duration_seconds",
+		Help:    "Duración de las consultas de disponibilidad de mesas.",
+		Buckets: prometheus.DefBuckets,
+	})
+
+	availabilityQueryDocuments = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "availability_query_documents",
+		Help:    "Documentos leídos de Mongo por cada consulta de disponibilidad.",
+		Buckets: prometheus.ExponentialBuckets(1, 4, 8),
+	})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		reservationsCreated,
+		reservationStatusChanges,
+		availabilityQueryDuration,
+		availabilityQueryDocuments,
+	)
+}
+
+// ReservationCreated cuenta una reserva nueva.
+func ReservationCreated(status, source string) {
+	reservationsCreated.WithLabelValues(status, source).Inc()
+}
+
+// ReservationStatusChanged cuenta el paso de una reserva al estado indicado.
+func ReservationStatusChanged(status string) {
+	reservationStatusChanges.WithLabelValues(status).Inc()
+}
+
+// ObserveAvailabilityQuery registra el coste de una consulta de
+// disponibilidad: su duración y los documentos que tuvo que leer.
+func ObserveAvailabilityQuery(duration time.Duration, documents int) {
+	availabilityQueryDuration.Observe(duration.Seconds())
+	availabilityQueryDocuments.Observe(float64(documents))
+}
+
+// Serve expone /metrics en la dirección de METRICS_ADDR (DefaultAddr si no
+// se define; "off" lo desactiva). No bloquea.
+func Serve() {
+	addr := os.Getenv("METRICS_ADDR")
+	if addr == "off" {
+		slog.Info("metrics endpoint disabled: METRICS_ADDR=off")
+		return
+	}
+	if addr == "" {
+		addr = DefaultAddr
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry}))
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	go func() {
+		slog.Info("metrics endpoint listening", "addr", addr)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("metrics endpoint stopped", "error", err)
+		}
+	}()
+}
+
+// seatedCoversCollector consulta los comensales sentados en el momento del
+// scrape, para que el valor no dependa del estado en memoria del proceso.
+type seatedCoversCollector struct {
+	desc  *prometheus.Desc
+	fetch func(ctx context.Context) (map[string]int, error)
+}
+
+// RegisterSeatedCovers registra el gauge seated_covers, calculado en cada
+// scrape con fetch (comensales sentados por restaurante).
+func RegisterSeatedCovers(fetch func(ctx context.Context) (map[string]int, error)) {
+	Registry.MustRegister(&seatedCoversCollector{
+		desc: prometheus.NewDesc("seated_covers",
+			"Comensales sentados actualmente, por restaurante.",
+			[]string{"restaurant_id"}, nil),
+		fetch: fetch,
+	})
+}
+
+func (c *seatedCoversCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *seatedCoversCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	covers, err := c.fetch(ctx)
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(c.desc, err)
+		return
+	}
+	for restaurantID, count := range covers {
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(count), restaurantID)
+	}
+}
@@ -0,0 +1,39 @@
+package metrics
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.mongodb.org/mongo-driver/event"
+)
+
+var (
+	mongoCommandSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "mongodb_command_duration_seconds",
+		Help:    "Latencia de los comandos enviados a MongoDB, por comando.",
+		Buckets: []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"command"})
+
+	mongoCommandErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "mongodb_command_errors_total",
+		Help: "Comandos de MongoDB que terminaron en error, por comando.",
+	}, []string{"command"})
+)
+
+func init() {
+	Registry.MustRegister(mongoCommandSeconds, mongoCommandErrors)
+}
+
+// CommandMonitor devuelve un monitor para options.Client().SetMonitor que
+// registra la latencia y los errores de cada comando.
+func CommandMonitor() *event.CommandMonitor {
+	return &event.CommandMonitor{
+		Succeeded: func(_ context.Context, e *event.CommandSucceededEvent) {
+			mongoCommandSeconds.WithLabelValues(e.CommandName).Observe(e.Duration.Seconds())
+		},
+		Failed: func(_ context.Context, e *event.CommandFailedEvent) {
+			mongoCommandSeconds.WithLabelValues(e.CommandName).Observe(e.Duration.Seconds())
+			mongoCommandErrors.WithLabelValues(e.CommandName).Inc()
+		},
+	}
+}
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// AuditEntry registra un cambio sobre una entidad. Before y After guardan
+// el documento completo antes y después del cambio (vacíos al crear y al
+// borrar, respectivamente).
+type AuditEntry struct {
+	ID           string    `json:"id,omitempty" bson:"_id,omitempty"`
+	EntityType   string    `json:"entity_type"`
+	EntityId     string    `json:"entity_id"`
+	RestaurantId string    `json:"restaurant_id"`
+	Action       string    `json:"action"`
+	Actor        string    `json:"actor"`
+	Rpc          string    `json:"rpc"`
+	RequestId    string    `json:"request_id,omitempty"`
+	Before       bson.Raw  `json:"before,omitempty"`
+	After        bson.Raw  `json:"after,omitempty"`
+	CreateAt     time.Time `json:"create_at"`
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// GuestProfile son los datos de un cliente, identificado por su UserId.
+// VisitCount y LastVisit se actualizan al completarse sus reservas.
+type GuestProfile struct {
+	ID           string    `json:"id,omitempty" bson:"_id,omitempty"`
+	UserId       string    `json:"user_id"`
+	Name         string    `json:"name"`
+	Email        string    `json:"email,omitempty"`
+	Phone        string    `json:"phone,omitempty"`
+	Language     string    `json:"language,omitempty"`
+	Allergies    []string  `json:"allergies,omitempty"`
+	DietaryNotes string    `json:"dietary_notes,omitempty"`
+	Preferences  string    `json:"preferences,omitempty"`
+	Tags         []string  `json:"tags,omitempty"`
+	VisitCount   int       `json:"visit_count"`
+	LastVisit    time.Time `json:"last_visit,omitempty"`
+	CreateAt     time.Time `json:"create_at"`
+	UpdateAt     time.Time `json:"update_at,omitempty"`
+}
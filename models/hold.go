@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+type Hold struct {
+	ID              string    `json:"id,omitempty" bson:"_id,omitempty"`
+	Token           string    `json:"token"`
+	RestaurantId    string    `json:"restaurant_id"`
+	UserId          string    `json:"user_id"`
+	TableId         string    `json:"table_id"`
+	ReservationDate string    `json:"reservation_date"`
+	ReservationTime string    `json:"reservation_time"`
+	GuestCount      int       `json:"guest_count"`
+	ExpiresAt       time.Time `json:"expires_at"`
+	CreateAt        time.Time `json:"create_at"`
+}
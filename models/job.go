@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// Job es una tarea programada. Key la identifica de forma única (p. ej. el
+// recordatorio de una reserva concreta), así reprogramarla no la duplica.
+type Job struct {
+	ID          string            `json:"id,omitempty" bson:"_id,omitempty"`
+	Type        string            `json:"type"`
+	Key         string            `json:"key"`
+	Data        map[string]string `json:"data,omitempty"`
+	Status      string            `json:"status"`
+	RunAt       time.Time         `json:"run_at"`
+	Attempts    int               `json:"attempts"`
+	LockedBy    string            `json:"locked_by,omitempty"`
+	LockedUntil time.Time         `json:"locked_until,omitempty"`
+	LastError   string            `json:"last_error,omitempty"`
+	CreateAt    time.Time         `json:"create_at"`
+	UpdateAt    time.Time         `json:"update_at,omitempty"`
+	CompletedAt time.Time         `json:"completed_at,omitempty"`
+}
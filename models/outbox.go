@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// OutboxEvent es un evento de dominio pendiente de publicar. Se escribe en
+// la misma transacción que el cambio que lo origina.
+type OutboxEvent struct {
+	ID           string    `json:"id,omitempty" bson:"_id,omitempty"`
+	Type         string    `json:"type"`
+	AggregateId  string    `json:"aggregate_id"`
+	RestaurantId string    `json:"restaurant_id"`
+	Payload      string    `json:"payload"`
+	OccurredAt   time.Time `json:"occurred_at"`
+	Published    bool      `json:"published"`
+	PublishedAt  time.Time `json:"published_at,omitempty"`
+	Attempts     int       `json:"attempts"`
+	LastError    string    `json:"last_error,omitempty"`
+}
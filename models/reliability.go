@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// GuestReliability acumula el historial de asistencia de un cliente.
+type GuestReliability struct {
+	ID                string    `json:"id,omitempty" bson:"_id,omitempty"`
+	UserId            string    `json:"user_id"`
+	Completed         int       `json:"completed"`
+	NoShows           int       `json:"no_shows"`
+	Cancellations     int       `json:"cancellations"`
+	LateCancellations int       `json:"late_cancellations"`
+	UpdateAt          time.Time `json:"update_at,omitempty"`
+}
@@ -3,15 +3,78 @@ package models
 import "time"
 
 type Reservation struct {
-	ID              string    `json:"id,omitempty" bson:"_id,omitempty"`
-	UserId          string    `json:"user_id"`
-	TableId         string    `json:"table_id"`
-	ReservationDate string    `json:"reservation_date"`
-	ReservationTime string    `json:"reservation_time"`
-	GuestCount      int       `json:"guest_count"`
-	Status          string    `json:"status"`
-	CreateAt        time.Time `json:"create_at"`
-	UpdateAt        time.Time `json:"update_at,omitempty"`
+	ID               string    `json:"id,omitempty" bson:"_id,omitempty"`
+	RestaurantId     string    `json:"restaurant_id"`
+	UserId           string    `json:"user_id"`
+	TableId          string    `json:"table_id"`
+	ReservationDate  string    `json:"reservation_date"`
+	ReservationTime  string    `json:"reservation_time"`
+	GuestCount       int       `json:"guest_count"`
+	Status           string    `json:"status"`
+	Source           string    `json:"source"`
+	ConfirmationCode string    `json:"confirmation_code,omitempty" bson:"confirmationcode,omitempty"`
+	SeatedAt         time.Time `json:"seated_at,omitempty"`
+	// BlockedSlots son las franjas siguientes a ReservationTime que la
+	// reserva también ocupa, como el resto del turno de un walk-in.
+	BlockedSlots        []string             `json:"blocked_slots,omitempty" bson:"blockedslots,omitempty"`
+	SeriesId            string               `json:"series_id,omitempty"`
+	OccurrenceIndex     int                  `json:"occurrence_index,omitempty"`
+	GuestName           string               `json:"guest_name,omitempty"`
+	GuestEmail          string               `json:"guest_email,omitempty"`
+	GuestPhone          string               `json:"guest_phone,omitempty"`
+	Language            string               `json:"language,omitempty"`
+	Occasion            string               `json:"occasion,omitempty"`
+	DietaryRestrictions []string             `json:"dietary_restrictions,omitempty" bson:"dietaryrestrictions,omitempty"`
+	Notes               string               `json:"notes,omitempty"`
+	StaffNotes          string               `json:"staff_notes,omitempty"`
+	Deleted             bool                 `json:"deleted,omitempty"`
+	DeletedAt           time.Time            `json:"deleted_at,omitempty"`
+	DeletedBy           string               `json:"deleted_by,omitempty"`
+	Cancellation        *CancellationOutcome `json:"cancellation,omitempty" bson:"cancellation,omitempty"`
+	Deposit             *Deposit             `json:"deposit,omitempty" bson:"deposit,omitempty"`
+	Version             int                  `json:"version"`
+	History             []ReservationChange  `json:"history,omitempty" bson:"history,omitempty"`
+	CreateAt            time.Time            `json:"create_at"`
+	UpdateAt            time.Time            `json:"update_at,omitempty"`
 }
 
 type Reservations []Reservation
+
+// Deposit es el depósito de una reserva y su pago en el proveedor.
+type Deposit struct {
+	AmountCents   int       `json:"amount_cents"`
+	Currency      string    `json:"currency"`
+	PaymentId     string    `json:"payment_id"`
+	CheckoutUrl   string    `json:"checkout_url"`
+	Status        string    `json:"status"`
+	ExpiresAt     time.Time `json:"expires_at"`
+	PaidAt        time.Time `json:"paid_at,omitempty"`
+	RefundedCents int       `json:"refunded_cents,omitempty"`
+	RefundedAt    time.Time `json:"refunded_at,omitempty"`
+}
+
+// CancellationOutcome registra la política aplicada al cancelar la reserva
+// y la penalización resultante.
+type CancellationOutcome struct {
+	Policy                string    `json:"policy"`
+	FreeCancellationHours int       `json:"free_cancellation_hours"`
+	Late                  bool      `json:"late"`
+	PenaltyCents          int       `json:"penalty_cents"`
+	CancelledAt           time.Time `json:"cancelled_at"`
+	CancelledBy           string    `json:"cancelled_by"`
+}
+
+// ReservationChange es una entrada del historial de una reserva: quién la
+// modificó, cuándo y qué campos cambiaron. Version es la versión resultante.
+type ReservationChange struct {
+	Version   int           `json:"version"`
+	Actor     string        `json:"actor"`
+	ChangedAt time.Time     `json:"changed_at"`
+	Changes   []FieldChange `json:"changes"`
+}
+
+type FieldChange struct {
+	Field    string `json:"field"`
+	OldValue string `json:"old_value"`
+	NewValue string `json:"new_value"`
+}
@@ -0,0 +1,67 @@
+package models
+
+import "time"
+
+type Restaurant struct {
+	ID       string             `json:"id,omitempty" bson:"_id,omitempty"`
+	Name     string             `json:"name"`
+	Address  string             `json:"address"`
+	Timezone string             `json:"timezone"`
+	Schedule []OpeningHours     `json:"schedule"`
+	Policies RestaurantPolicies `json:"policies"`
+	CreateAt time.Time          `json:"create_at"`
+	UpdateAt time.Time          `json:"update_at,omitempty"`
+}
+
+// OpeningHours indica el horario de un día de la semana (0 = domingo).
+// Open y Close usan el formato HH:MM en la zona horaria del restaurante.
+type OpeningHours struct {
+	Weekday int    `json:"weekday"`
+	Open    string `json:"open"`
+	Close   string `json:"close"`
+}
+
+// RestaurantPolicies son las reglas de reserva del restaurante. Los umbrales
+// de fiabilidad se comparan con la puntuación del cliente (0-100) y 0 los
+// desactiva.
+type RestaurantPolicies struct {
+	MaxGuestCount             int `json:"max_guest_count"`
+	TurnTimeMinutes           int `json:"turn_time_minutes"`
+	MaxAdvanceDays            int `json:"max_advance_days"`
+	ReliabilityConfirmBelow   int `json:"reliability_confirm_below"`
+	ReliabilityMaxGuestsBelow int `json:"reliability_max_guests_below"`
+	ReliabilityMaxGuests      int `json:"reliability_max_guests"`
+	ReliabilityBlockBelow     int `json:"reliability_block_below"`
+
+	Cancellation CancellationPolicy `json:"cancellation"`
+	Deposit      DepositPolicy      `json:"deposit"`
+}
+
+// DepositPolicy exige un depósito por comensal a los grupos de
+// MinGuestCount o más personas y a todas las reservas en las fechas de
+// Events. Sin AmountPerGuestCents no se piden depósitos. Si el depósito no
+// se paga en PaymentWindowMinutes la reserva se cancela.
+type DepositPolicy struct {
+	AmountPerGuestCents  int            `json:"amount_per_guest_cents"`
+	Currency             string         `json:"currency"`
+	MinGuestCount        int            `json:"min_guest_count"`
+	Events               []SpecialEvent `json:"events,omitempty"`
+	PaymentWindowMinutes int            `json:"payment_window_minutes"`
+}
+
+// CancellationPolicy define hasta cuándo se cancela sin penalización. Sin
+// FreeCancellationHours se usa LATE_CANCELLATION_HOURS. Las reservas en
+// fechas de NonCancellableEvents no las puede cancelar el cliente.
+type CancellationPolicy struct {
+	FreeCancellationHours int            `json:"free_cancellation_hours"`
+	LatePenaltyCents      int            `json:"late_penalty_cents"`
+	NonCancellableEvents  []SpecialEvent `json:"non_cancellable_events,omitempty"`
+}
+
+// SpecialEvent es una fecha especial del restaurante, en formato dd-mm-yyyy.
+type SpecialEvent struct {
+	Name string `json:"name"`
+	Date string `json:"date"`
+}
+
+type Restaurants []Restaurant
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+type ReservationSeries struct {
+	ID              string    `json:"id,omitempty" bson:"_id,omitempty"`
+	RestaurantId    string    `json:"restaurant_id"`
+	UserId          string    `json:"user_id"`
+	TableId         string    `json:"table_id"`
+	StartDate       string    `json:"start_date"`
+	ReservationTime string    `json:"reservation_time"`
+	GuestCount      int       `json:"guest_count"`
+	RecurrenceRule  string    `json:"recurrence_rule"`
+	Status          string    `json:"status"`
+	CreateAt        time.Time `json:"create_at"`
+	UpdateAt        time.Time `json:"update_at,omitempty"`
+}
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+type WaitlistEntry struct {
+	ID              string    `json:"id,omitempty" bson:"_id,omitempty"`
+	RestaurantId    string    `json:"restaurant_id"`
+	UserId          string    `json:"user_id"`
+	ReservationDate string    `json:"reservation_date"`
+	ReservationTime string    `json:"reservation_time"`
+	GuestCount      int       `json:"guest_count"`
+	AutoPromote     bool      `json:"auto_promote"`
+	Status          string    `json:"status"`
+	OfferedTableId  string    `json:"offered_table_id,omitempty"`
+	OfferExpiresAt  time.Time `json:"offer_expires_at,omitempty"`
+	ReservationId   string    `json:"reservation_id,omitempty"`
+	CreateAt        time.Time `json:"create_at"`
+	UpdateAt        time.Time `json:"update_at,omitempty"`
+}
+
+type WaitlistEntries []WaitlistEntry
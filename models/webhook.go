@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+// Webhook es una suscripción de un socio a los eventos de reservas. Si
+// RestaurantId está vacío recibe los eventos de todos los restaurantes, y si
+// EventTypes está vacío, todos los tipos.
+type Webhook struct {
+	ID           string    `json:"id,omitempty" bson:"_id,omitempty"`
+	RestaurantId string    `json:"restaurant_id,omitempty"`
+	Url          string    `json:"url"`
+	EventTypes   []string  `json:"event_types,omitempty"`
+	Secret       string    `json:"-"`
+	Active       bool      `json:"active"`
+	CreatedBy    string    `json:"created_by"`
+	CreateAt     time.Time `json:"create_at"`
+	UpdateAt     time.Time `json:"update_at,omitempty"`
+}
+
+// WebhookDelivery es el registro de la entrega de un evento a un webhook.
+// Las entregas que agotan los reintentos quedan en estado fallida como
+// registro de mensajes muertos.
+type WebhookDelivery struct {
+	ID             string    `json:"id,omitempty" bson:"_id,omitempty"`
+	WebhookId      string    `json:"webhook_id"`
+	EventId        string    `json:"event_id"`
+	EventType      string    `json:"event_type"`
+	Payload        string    `json:"payload"`
+	Status         string    `json:"status"`
+	Attempts       int       `json:"attempts"`
+	LastStatusCode int       `json:"last_status_code,omitempty"`
+	LastError      string    `json:"last_error,omitempty"`
+	NextAttemptAt  time.Time `json:"next_attempt_at"`
+	CreateAt       time.Time `json:"create_at"`
+	DeliveredAt    time.Time `json:"delivered_at,omitempty"`
+}
@@ -0,0 +1,44 @@
+package notifications
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+)
+
+// LogChannel solo registra las notificaciones. Es el canal por defecto en
+// local.
+type LogChannel struct{}
+
+func (LogChannel) Name() string { return "log" }
+
+func (LogChannel) Send(ctx context.Context, msg Message) error {
+	slog.InfoContext(ctx, "notification",
+		"kind", msg.Kind,
+		"language", msg.Language,
+		"email", redactEmail(msg.To.Email),
+		"phone", redactPhone(msg.To.Phone),
+		"subject", msg.Subject,
+	)
+	slog.DebugContext(ctx, "notification body", "kind", msg.Kind, "body", msg.Body)
+	return nil
+}
+
+// redactEmail deja solo la primera letra del usuario y el dominio, lo
+// justo para reconocer a qué cuenta iba la notificación sin guardar la
+// dirección en los logs.
+func redactEmail(email string) string {
+	user, domain, ok := strings.Cut(email, "@")
+	if !ok || user == "" {
+		return ""
+	}
+	return user[:1] + "***@" + domain
+}
+
+// redactPhone deja solo los cuatro últimos dígitos.
+func redactPhone(phone string) string {
+	if len(phone) <= 4 {
+		return ""
+	}
+	return "***" + phone[len(phone)-4:]
+}
@@ -0,0 +1,183 @@
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+
+	"ms-reservas/events"
+	m "ms-reservas/models"
+)
+
+// ErrNoRecipient indica que el cliente no tiene la dirección que usa el
+// canal (email o teléfono). No es un fallo: el canal simplemente no aplica.
+var ErrNoRecipient = errors.New("recipient has no address for this channel")
+
+// Recipient son los datos de contacto del cliente.
+type Recipient struct {
+	Name  string
+	Email string
+	Phone string
+}
+
+// Message es una notificación ya renderizada.
+type Message struct {
+	Kind     string
+	Language string
+	To       Recipient
+	Subject  string
+	Body     string
+}
+
+// Channel envía notificaciones por un medio concreto.
+type Channel interface {
+	Name() string
+	Send(ctx context.Context, msg Message) error
+}
+
+// Notifier renderiza las notificaciones de una reserva y las envía por
+// todos sus canales. RestaurantName, si está definido, da el nombre del
+// restaurante para las plantillas.
+type Notifier struct {
+	Channels       []Channel
+	RestaurantName func(ctx context.Context, restaurantID string) (string, error)
+}
+
+// Notify envía la notificación kind de la reserva. Solo devuelve error si
+// ningún canal pudo entregarla, para que quien llama pueda reintentar sin
+// duplicar lo ya enviado en el caso habitual.
+func (n *Notifier) Notify(ctx context.Context, kind string, reservation m.Reservation) error {
+	lang := reservation.Language
+	if !SupportedLanguage(lang) {
+		lang = DefaultLanguage
+	}
+	data := TemplateData{
+		GuestName:        reservation.GuestName,
+		ReservationID:    reservation.ID,
+		ConfirmationCode: reservation.ConfirmationCode,
+		Date:             reservation.ReservationDate,
+		Time:             reservation.ReservationTime,
+		GuestCount:       reservation.GuestCount,
+	}
+	if n.RestaurantName != nil {
+		name, err := n.RestaurantName(ctx, reservation.RestaurantId)
+		if err != nil {
+			slog.WarnContext(ctx, "failed to find restaurant for notification", "restaurant_id", reservation.RestaurantId, "error", err)
+		}
+		data.RestaurantName = name
+	}
+	subject, body, err := Render(kind, lang, data)
+	if err != nil {
+		return err
+	}
+	msg := Message{
+		Kind:     kind,
+		Language: lang,
+		To:       Recipient{Name: reservation.GuestName, Email: reservation.GuestEmail, Phone: reservation.GuestPhone},
+		Subject:  subject,
+		Body:     body,
+	}
+
+	var errs []error
+	sent := false
+	for _, channel := range n.Channels {
+		err := channel.Send(ctx, msg)
+		switch {
+		case err == nil:
+			sent = true
+		case errors.Is(err, ErrNoRecipient):
+		default:
+			slog.WarnContext(ctx, "failed to send notification", "channel", channel.Name(), "kind", kind, "reservation_id", reservation.ID, "error", err)
+			errs = append(errs, fmt.Errorf("%s: %w", channel.Name(), err))
+		}
+	}
+	if !sent && len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	return nil
+}
+
+// EventKind devuelve la notificación que genera el evento, o "" si ninguna,
+// junto con su contenido.
+func EventKind(event events.Event) (string, events.ReservationPayload, error) {
+	var payload events.ReservationPayload
+	if err := json.Unmarshal(event.Payload, &payload); err != nil {
+		return "", payload, fmt.Errorf("invalid reservation event payload: %w", err)
+	}
+	return eventKind(event.Type, payload), payload, nil
+}
+
+// notifiedFields son los cambios que el cliente debe conocer.
+var notifiedFields = []string{"reservationdate", "reservationtime", "guestcount"}
+
+// eventKind decide qué notificación genera un evento, o "" si ninguna.
+func eventKind(eventType string, payload events.ReservationPayload) string {
+	switch eventType {
+	case events.ReservationCreated:
+		// Las llegadas sin reserva se crean ya sentadas: no hay nada que
+		// confirmar al cliente.
+		switch payload.Reservation.Status {
+		case "confirmada":
+			return KindConfirmation
+		case "por_confirmar":
+			return KindConfirmationRequired
+		}
+	case events.ReservationCancelled:
+		return KindCancellation
+	case events.ReservationReminder:
+		return KindReminder
+	case events.ReservationUpdated:
+		// Confirmar una reserva pendiente (de pago o de reconfirmación)
+		// equivale a crearla confirmada.
+		for _, change := range payload.Changes {
+			if change.Field == "status" && change.NewValue == "confirmada" {
+				return KindConfirmation
+			}
+		}
+		for _, change := range payload.Changes {
+			if slices.Contains(notifiedFields, change.Field) {
+				return KindModification
+			}
+		}
+	}
+	return ""
+}
+
+// ChannelsFromEnv crea los canales listados en NOTIFICATION_CHANNELS,
+// separados por comas: log, smtp o sms. Por defecto solo log; "none" los
+// desactiva todos.
+func ChannelsFromEnv() ([]Channel, error) {
+	names := os.Getenv("NOTIFICATION_CHANNELS")
+	if names == "" {
+		names = "log"
+	}
+	var channels []Channel
+	for _, name := range strings.Split(names, ",") {
+		switch name = strings.ToLower(strings.TrimSpace(name)); name {
+		case "none":
+			return nil, nil
+		case "log":
+			channels = append(channels, LogChannel{})
+		case "smtp":
+			channel, err := SMTPChannelFromEnv()
+			if err != nil {
+				return nil, err
+			}
+			channels = append(channels, channel)
+		case "sms":
+			channel, err := SMSChannelFromEnv()
+			if err != nil {
+				return nil, err
+			}
+			channels = append(channels, channel)
+		default:
+			return nil, fmt.Errorf("invalid notification channel %q, expected log, smtp, sms or none", name)
+		}
+	}
+	return channels, nil
+}
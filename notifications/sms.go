@@ -0,0 +1,77 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+// SMSChannel envía las notificaciones a una pasarela SMS genérica por HTTP:
+// un POST JSON {"to": ..., "message": ...} con el token, si lo hay, como
+// Bearer.
+type SMSChannel struct {
+	URL    string
+	Token  string
+	Client *http.Client
+}
+
+// SMSChannelFromEnv lee SMS_GATEWAY_URL y, opcionalmente, SMS_GATEWAY_TOKEN.
+func SMSChannelFromEnv() (*SMSChannel, error) {
+	gateway := os.Getenv("SMS_GATEWAY_URL")
+	u, err := url.Parse(gateway)
+	if gateway == "" || err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil, fmt.Errorf("SMS_GATEWAY_URL must be an http or https url for the sms notification channel")
+	}
+	return &SMSChannel{
+		URL:    gateway,
+		Token:  os.Getenv("SMS_GATEWAY_TOKEN"),
+		Client: &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+func (c *SMSChannel) Name() string { return "sms" }
+
+type smsRequest struct {
+	To      string `json:"to"`
+	Message string `json:"message"`
+}
+
+func (c *SMSChannel) Send(ctx context.Context, msg Message) error {
+	if msg.To.Phone == "" {
+		return ErrNoRecipient
+	}
+	// El asunto ya resume el mensaje; en SMS se envía delante del cuerpo.
+	body, err := json.Marshal(smsRequest{To: msg.To.Phone, Message: msg.Subject + "\n\n" + msg.Body})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+
+	client := c.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("sms gateway responded %d", resp.StatusCode)
+	}
+	return nil
+}
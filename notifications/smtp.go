@@ -0,0 +1,123 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"os"
+	"time"
+)
+
+// SMTPChannel envía las notificaciones por email. Usa STARTTLS si el
+// servidor lo ofrece y solo se autentica si hay usuario, así funciona contra
+// un servidor SMTP falso en local.
+type SMTPChannel struct {
+	Addr     string
+	From     string
+	Username string
+	Password string
+	Timeout  time.Duration
+}
+
+// SMTPChannelFromEnv lee SMTP_ADDR (host:puerto), SMTP_FROM y, opcionalmente,
+// SMTP_USERNAME y SMTP_PASSWORD.
+func SMTPChannelFromEnv() (*SMTPChannel, error) {
+	channel := &SMTPChannel{
+		Addr:     os.Getenv("SMTP_ADDR"),
+		From:     os.Getenv("SMTP_FROM"),
+		Username: os.Getenv("SMTP_USERNAME"),
+		Password: os.Getenv("SMTP_PASSWORD"),
+	}
+	if channel.Addr == "" || channel.From == "" {
+		return nil, fmt.Errorf("SMTP_ADDR and SMTP_FROM are required for the smtp notification channel")
+	}
+	if _, err := mail.ParseAddress(channel.From); err != nil {
+		return nil, fmt.Errorf("invalid SMTP_FROM: %w", err)
+	}
+	return channel, nil
+}
+
+func (c *SMTPChannel) Name() string { return "smtp" }
+
+func (c *SMTPChannel) Send(ctx context.Context, msg Message) error {
+	if msg.To.Email == "" {
+		return ErrNoRecipient
+	}
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", c.Addr)
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	host, _, err := net.SplitHostPort(c.Addr)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err = client.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return err
+		}
+	}
+	if c.Username != "" {
+		if err = client.Auth(smtp.PlainAuth("", c.Username, c.Password, host)); err != nil {
+			return err
+		}
+	}
+	if err = client.Mail(c.From); err != nil {
+		return err
+	}
+	if err = client.Rcpt(msg.To.Email); err != nil {
+		return err
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err = w.Write(c.compose(msg)); err != nil {
+		w.Close()
+		return err
+	}
+	if err = w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// compose construye el mensaje RFC 5322 en texto plano UTF-8.
+func (c *SMTPChannel) compose(msg Message) []byte {
+	to := (&mail.Address{Name: msg.To.Name, Address: msg.To.Email}).String()
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", c.From)
+	fmt.Fprintf(&buf, "To: %s\r\n", to)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	fmt.Fprintf(&buf, "Content-Language: %s\r\n", msg.Language)
+	buf.WriteString("\r\n")
+	buf.Write(bytes.ReplaceAll([]byte(msg.Body), []byte("\n"), []byte("\r\n")))
+	return buf.Bytes()
+}
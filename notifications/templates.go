@@ -0,0 +1,161 @@
+package notifications
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"text/template"
+)
+
+// Tipos de mensaje
+const (
+	KindConfirmation = "confirmation"
+	// KindConfirmationRequired pide al cliente que reconfirme la reserva.
+	KindConfirmationRequired = "confirmation_required"
+	KindModification         = "modification"
+	KindCancellation         = "cancellation"
+	KindReminder             = "reminder"
+)
+
+// DefaultLanguage se usa cuando la reserva no indica idioma.
+const DefaultLanguage = "es"
+
+// Languages son los idiomas con plantillas.
+var Languages = []string{"es", "en"}
+
+// SupportedLanguage indica si hay plantillas para lang.
+func SupportedLanguage(lang string) bool {
+	return slices.Contains(Languages, lang)
+}
+
+// TemplateData son los datos disponibles en las plantillas.
+type TemplateData struct {
+	GuestName      string
+	RestaurantName string
+	ReservationID  string
+	// ConfirmationCode es la referencia que da el cliente; las reservas
+	// anteriores a los códigos usan ReservationID.
+	ConfirmationCode string
+	Date             string
+	Time             string
+	GuestCount       int
+}
+
+type messageTemplate struct {
+	subject string
+	body    string
+}
+
+var templateSources = map[string]map[string]messageTemplate{
+	"es": {
+		KindConfirmation: {
+			subject: "Reserva confirmada en {{.RestaurantName}}",
+			body: "Hola{{with .GuestName}} {{.}}{{end}},\n\n" +
+				"Tu reserva en {{.RestaurantName}} para {{.GuestCount}} personas el {{.Date}} a las {{.Time}} está confirmada.\n\n" +
+				"Referencia: {{or .ConfirmationCode .ReservationID}}\n",
+		},
+		KindConfirmationRequired: {
+			subject: "Confirma tu reserva en {{.RestaurantName}}",
+			body: "Hola{{with .GuestName}} {{.}}{{end}},\n\n" +
+				"Hemos recibido tu reserva en {{.RestaurantName}} para {{.GuestCount}} personas el {{.Date}} a las {{.Time}}. " +
+				"Necesitamos que la confirmes para mantenerla.\n\n" +
+				"Referencia: {{or .ConfirmationCode .ReservationID}}\n",
+		},
+		KindModification: {
+			subject: "Reserva modificada en {{.RestaurantName}}",
+			body: "Hola{{with .GuestName}} {{.}}{{end}},\n\n" +
+				"Tu reserva en {{.RestaurantName}} ha cambiado. Ahora es para {{.GuestCount}} personas el {{.Date}} a las {{.Time}}.\n\n" +
+				"Referencia: {{or .ConfirmationCode .ReservationID}}\n",
+		},
+		KindCancellation: {
+			subject: "Reserva cancelada en {{.RestaurantName}}",
+			body: "Hola{{with .GuestName}} {{.}}{{end}},\n\n" +
+				"Tu reserva en {{.RestaurantName}} del {{.Date}} a las {{.Time}} ha sido cancelada.\n\n" +
+				"Referencia: {{or .ConfirmationCode .ReservationID}}\n",
+		},
+		KindReminder: {
+			subject: "Recordatorio de tu reserva en {{.RestaurantName}}",
+			body: "Hola{{with .GuestName}} {{.}}{{end}},\n\n" +
+				"Te recordamos tu reserva en {{.RestaurantName}} para {{.GuestCount}} personas el {{.Date}} a las {{.Time}}.\n\n" +
+				"Referencia: {{or .ConfirmationCode .ReservationID}}\n",
+		},
+	},
+	"en": {
+		KindConfirmation: {
+			subject: "Your reservation at {{.RestaurantName}} is confirmed",
+			body: "Hello{{with .GuestName}} {{.}}{{end}},\n\n" +
+				"Your reservation at {{.RestaurantName}} for {{.GuestCount}} guests on {{.Date}} at {{.Time}} is confirmed.\n\n" +
+				"Reference: {{or .ConfirmationCode .ReservationID}}\n",
+		},
+		KindConfirmationRequired: {
+			subject: "Please confirm your reservation at {{.RestaurantName}}",
+			body: "Hello{{with .GuestName}} {{.}}{{end}},\n\n" +
+				"We have received your reservation at {{.RestaurantName}} for {{.GuestCount}} guests on {{.Date}} at {{.Time}}. " +
+				"Please confirm it to keep your table.\n\n" +
+				"Reference: {{or .ConfirmationCode .ReservationID}}\n",
+		},
+		KindModification: {
+			subject: "Your reservation at {{.RestaurantName}} has changed",
+			body: "Hello{{with .GuestName}} {{.}}{{end}},\n\n" +
+				"Your reservation at {{.RestaurantName}} has been updated. It is now for {{.GuestCount}} guests on {{.Date}} at {{.Time}}.\n\n" +
+				"Reference: {{or .ConfirmationCode .ReservationID}}\n",
+		},
+		KindCancellation: {
+			subject: "Your reservation at {{.RestaurantName}} has been cancelled",
+			body: "Hello{{with .GuestName}} {{.}}{{end}},\n\n" +
+				"Your reservation at {{.RestaurantName}} on {{.Date}} at {{.Time}} has been cancelled.\n\n" +
+				"Reference: {{or .ConfirmationCode .ReservationID}}\n",
+		},
+		KindReminder: {
+			subject: "Reminder: your reservation at {{.RestaurantName}}",
+			body: "Hello{{with .GuestName}} {{.}}{{end}},\n\n" +
+				"This is a reminder of your reservation at {{.RestaurantName}} for {{.GuestCount}} guests on {{.Date}} at {{.Time}}.\n\n" +
+				"Reference: {{or .ConfirmationCode .ReservationID}}\n",
+		},
+	},
+}
+
+type compiledTemplate struct {
+	subject *template.Template
+	body    *template.Template
+}
+
+// templates se compila al arrancar: una plantilla inválida es un error de
+// programación.
+var templates = compileTemplates()
+
+func compileTemplates() map[string]map[string]compiledTemplate {
+	compiled := map[string]map[string]compiledTemplate{}
+	for lang, kinds := range templateSources {
+		compiled[lang] = map[string]compiledTemplate{}
+		for kind, source := range kinds {
+			compiled[lang][kind] = compiledTemplate{
+				subject: template.Must(template.New(lang + "." + kind + ".subject").Parse(source.subject)),
+				body:    template.Must(template.New(lang + "." + kind + ".body").Parse(source.body)),
+			}
+		}
+	}
+	return compiled
+}
+
+// Render genera el asunto y el cuerpo de un mensaje. Si lang no tiene
+// plantillas se usa DefaultLanguage.
+func Render(kind, lang string, data TemplateData) (subject, body string, err error) {
+	if !SupportedLanguage(lang) {
+		lang = DefaultLanguage
+	}
+	tmpl, ok := templates[lang][kind]
+	if !ok {
+		return "", "", fmt.Errorf("unknown notification kind %q", kind)
+	}
+	var buf bytes.Buffer
+	if err = tmpl.subject.Execute(&buf, data); err != nil {
+		return "", "", err
+	}
+	subject = buf.String()
+	buf.Reset()
+	if err = tmpl.body.Execute(&buf, data); err != nil {
+		return "", "", err
+	}
+	return subject, buf.String(), nil
+}
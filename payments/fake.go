@@ -0,0 +1,121 @@
+package payments
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// FakeProvider es un proveedor en memoria para desarrollo local y pruebas.
+type FakeProvider struct {
+	mu          sync.Mutex
+	autoSucceed bool
+	next        int
+	payments    map[string]*Payment
+	byReference map[string]string
+}
+
+func NewFakeProvider(autoSucceed bool) *FakeProvider {
+	return &FakeProvider{
+		autoSucceed: autoSucceed,
+		payments:    map[string]*Payment{},
+		byReference: map[string]string{},
+	}
+}
+
+func (p *FakeProvider) CreatePayment(_ context.Context, req PaymentRequest) (Payment, error) {
+	if req.AmountCents <= 0 {
+		return Payment{}, fmt.Errorf("payment amount must be positive")
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if id, ok := p.byReference[req.Reference]; ok {
+		return *p.payments[id], nil
+	}
+	p.next++
+	id := fmt.Sprintf("fake_pay_%d", p.next)
+	payment := &Payment{
+		ID:          id,
+		Reference:   req.Reference,
+		AmountCents: req.AmountCents,
+		Currency:    req.Currency,
+		Status:      StatusPending,
+		CheckoutURL: "https://payments.invalid/checkout/" + id,
+	}
+	p.payments[id] = payment
+	p.byReference[req.Reference] = id
+	return *payment, nil
+}
+
+func (p *FakeProvider) GetPayment(_ context.Context, id string) (Payment, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	payment, ok := p.payments[id]
+	if !ok {
+		return Payment{}, ErrPaymentNotFound
+	}
+	if p.autoSucceed && payment.Status == StatusPending {
+		payment.Status = StatusSucceeded
+	}
+	return *payment, nil
+}
+
+func (p *FakeProvider) CancelPayment(_ context.Context, id string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	payment, ok := p.payments[id]
+	if !ok {
+		return ErrPaymentNotFound
+	}
+	if payment.Status != StatusPending {
+		return fmt.Errorf("only pending payments can be cancelled, payment is %s", payment.Status)
+	}
+	payment.Status = StatusCancelled
+	return nil
+}
+
+func (p *FakeProvider) Refund(_ context.Context, paymentID string, amountCents int) (Refund, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	payment, ok := p.payments[paymentID]
+	if !ok {
+		return Refund{}, ErrPaymentNotFound
+	}
+	if payment.Status != StatusSucceeded {
+		return Refund{}, fmt.Errorf("only succeeded payments can be refunded, payment is %s", payment.Status)
+	}
+	if amountCents <= 0 || payment.RefundedCents+amountCents > payment.AmountCents {
+		return Refund{}, fmt.Errorf("invalid refund amount %d", amountCents)
+	}
+	payment.RefundedCents += amountCents
+	return Refund{
+		ID:          fmt.Sprintf("%s_refund_%d", paymentID, payment.RefundedCents),
+		PaymentID:   paymentID,
+		AmountCents: amountCents,
+	}, nil
+}
+
+// Complete marca un pago pendiente como pagado, como haría el cliente en la
+// página de pago.
+func (p *FakeProvider) Complete(id string) error {
+	return p.setStatus(id, StatusSucceeded)
+}
+
+// Fail marca un pago pendiente como rechazado.
+func (p *FakeProvider) Fail(id string) error {
+	return p.setStatus(id, StatusFailed)
+}
+
+func (p *FakeProvider) setStatus(id, status string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	payment, ok := p.payments[id]
+	if !ok {
+		return ErrPaymentNotFound
+	}
+	if payment.Status != StatusPending {
+		return fmt.Errorf("payment is already %s", payment.Status)
+	}
+	payment.Status = status
+	return nil
+}
@@ -0,0 +1,75 @@
+package payments
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Estados de un pago en el proveedor
+const (
+	StatusPending   = "pending"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+	StatusCancelled = "cancelled"
+)
+
+// ErrPaymentNotFound indica que el proveedor no conoce el pago.
+var ErrPaymentNotFound = errors.New("payment not found")
+
+// PaymentRequest describe el cobro de un depósito. Reference identifica la
+// operación en nuestro lado y sirve de clave de idempotencia: repetir la
+// petición con la misma referencia devuelve el mismo pago.
+type PaymentRequest struct {
+	Reference   string
+	AmountCents int
+	Currency    string
+	Description string
+	Metadata    map[string]string
+}
+
+// Payment es el estado de un pago en el proveedor. CheckoutURL es la página
+// en la que el cliente completa el pago.
+type Payment struct {
+	ID            string
+	Reference     string
+	AmountCents   int
+	Currency      string
+	Status        string
+	CheckoutURL   string
+	RefundedCents int
+}
+
+// Refund es una devolución, total o parcial, de un pago.
+type Refund struct {
+	ID          string
+	PaymentID   string
+	AmountCents int
+}
+
+// PaymentProvider abstrae la pasarela de pagos.
+type PaymentProvider interface {
+	CreatePayment(ctx context.Context, req PaymentRequest) (Payment, error)
+	GetPayment(ctx context.Context, id string) (Payment, error)
+	CancelPayment(ctx context.Context, id string) error
+	Refund(ctx context.Context, paymentID string, amountCents int) (Refund, error)
+}
+
+// ProviderFromEnv crea el proveedor indicado en PAYMENTS_PROVIDER: fake o
+// none (por defecto). Con none los depósitos quedan desactivados. El falso
+// da los pagos por completados al consultarlos si PAYMENTS_FAKE_AUTO_SUCCEED
+// es true; si no, hay que completarlos con FakeProvider.Complete.
+func ProviderFromEnv() (PaymentProvider, error) {
+	switch name := strings.ToLower(os.Getenv("PAYMENTS_PROVIDER")); name {
+	case "", "none":
+		return nil, nil
+	case "fake":
+		autoSucceed, _ := strconv.ParseBool(os.Getenv("PAYMENTS_FAKE_AUTO_SUCCEED"))
+		return NewFakeProvider(autoSucceed), nil
+	default:
+		return nil, fmt.Errorf("invalid PAYMENTS_PROVIDER %q, expected fake or none", name)
+	}
+}
@@ -25,6 +25,19 @@ const (
 	ReservationService_GetReservationsByDate_FullMethodName   = "/reservation.ReservationService/GetReservationsByDate"
 	ReservationService_UpdateReservation_FullMethodName       = "/reservation.ReservationService/UpdateReservation"
 	ReservationService_DeleteReservation_FullMethodName       = "/reservation.ReservationService/DeleteReservation"
+	ReservationService_WalkIn_FullMethodName                  = "/reservation.ReservationService/WalkIn"
+	ReservationService_HoldSlot_FullMethodName                = "/reservation.ReservationService/HoldSlot"
+	ReservationService_CreateReservationSeries_FullMethodName = "/reservation.ReservationService/CreateReservationSeries"
+	ReservationService_UpdateReservationSeries_FullMethodName = "/reservation.ReservationService/UpdateReservationSeries"
+	ReservationService_CancelReservationSeries_FullMethodName = "/reservation.ReservationService/CancelReservationSeries"
+	ReservationService_RestoreReservation_FullMethodName      = "/reservation.ReservationService/RestoreReservation"
+	ReservationService_GetReservationHistory_FullMethodName   = "/reservation.ReservationService/GetReservationHistory"
+	ReservationService_GetGuestReliability_FullMethodName     = "/reservation.ReservationService/GetGuestReliability"
+	ReservationService_ConfirmDepositPayment_FullMethodName   = "/reservation.ReservationService/ConfirmDepositPayment"
+	ReservationService_SearchReservations_FullMethodName      = "/reservation.ReservationService/SearchReservations"
+	ReservationService_GetReservationByCode_FullMethodName    = "/reservation.ReservationService/GetReservationByCode"
+	ReservationService_UpdateReservationByCode_FullMethodName = "/reservation.ReservationService/UpdateReservationByCode"
+	ReservationService_CancelReservationByCode_FullMethodName = "/reservation.ReservationService/CancelReservationByCode"
 )
 
 // ReservationServiceClient is the client API for ReservationService service.
@@ -37,6 +50,19 @@ type ReservationServiceClient interface {
 	GetReservationsByDate(ctx context.Context, in *GetReservationsByDateRequest, opts ...grpc.CallOption) (*Reservations, error)
 	UpdateReservation(ctx context.Context, in *UpdateReservationRequest, opts ...grpc.CallOption) (*Response, error)
 	DeleteReservation(ctx context.Context, in *DeleteReservationRequest, opts ...grpc.CallOption) (*Response, error)
+	WalkIn(ctx context.Context, in *WalkInRequest, opts ...grpc.CallOption) (*WalkInResponse, error)
+	HoldSlot(ctx context.Context, in *HoldSlotRequest, opts ...grpc.CallOption) (*HoldSlotResponse, error)
+	CreateReservationSeries(ctx context.Context, in *CreateReservationSeriesRequest, opts ...grpc.CallOption) (*ReservationSeriesResponse, error)
+	UpdateReservationSeries(ctx context.Context, in *UpdateReservationSeriesRequest, opts ...grpc.CallOption) (*ReservationSeriesResponse, error)
+	CancelReservationSeries(ctx context.Context, in *CancelReservationSeriesRequest, opts ...grpc.CallOption) (*ReservationSeriesResponse, error)
+	RestoreReservation(ctx context.Context, in *RestoreReservationRequest, opts ...grpc.CallOption) (*Response, error)
+	GetReservationHistory(ctx context.Context, in *GetReservationByIDRequest, opts ...grpc.CallOption) (*ReservationHistory, error)
+	GetGuestReliability(ctx context.Context, in *GetGuestReliabilityRequest, opts ...grpc.CallOption) (*GuestReliability, error)
+	ConfirmDepositPayment(ctx context.Context, in *GetReservationByIDRequest, opts ...grpc.CallOption) (*Response, error)
+	SearchReservations(ctx context.Context, in *SearchReservationsRequest, opts ...grpc.CallOption) (*Reservations, error)
+	GetReservationByCode(ctx context.Context, in *ReservationCodeRequest, opts ...grpc.CallOption) (*Reservation, error)
+	UpdateReservationByCode(ctx context.Context, in *UpdateReservationByCodeRequest, opts ...grpc.CallOption) (*Response, error)
+	CancelReservationByCode(ctx context.Context, in *ReservationCodeRequest, opts ...grpc.CallOption) (*Response, error)
 }
 
 type reservationServiceClient struct {
@@ -107,6 +133,136 @@ func (c *reservationServiceClient) DeleteReservation(ctx context.Context, in *De
 	return out, nil
 }
 
+func (c *reservationServiceClient) WalkIn(ctx context.Context, in *WalkInRequest, opts ...grpc.CallOption) (*WalkInResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(WalkInResponse)
+	err := c.cc.Invoke(ctx, ReservationService_WalkIn_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *reservationServiceClient) HoldSlot(ctx context.Context, in *HoldSlotRequest, opts ...grpc.CallOption) (*HoldSlotResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(HoldSlotResponse)
+	err := c.cc.Invoke(ctx, ReservationService_HoldSlot_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *reservationServiceClient) CreateReservationSeries(ctx context.Context, in *CreateReservationSeriesRequest, opts ...grpc.CallOption) (*ReservationSeriesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReservationSeriesResponse)
+	err := c.cc.Invoke(ctx, ReservationService_CreateReservationSeries_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *reservationServiceClient) UpdateReservationSeries(ctx context.Context, in *UpdateReservationSeriesRequest, opts ...grpc.CallOption) (*ReservationSeriesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReservationSeriesResponse)
+	err := c.cc.Invoke(ctx, ReservationService_UpdateReservationSeries_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *reservationServiceClient) CancelReservationSeries(ctx context.Context, in *CancelReservationSeriesRequest, opts ...grpc.CallOption) (*ReservationSeriesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReservationSeriesResponse)
+	err := c.cc.Invoke(ctx, ReservationService_CancelReservationSeries_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *reservationServiceClient) RestoreReservation(ctx context.Context, in *RestoreReservationRequest, opts ...grpc.CallOption) (*Response, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Response)
+	err := c.cc.Invoke(ctx, ReservationService_RestoreReservation_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *reservationServiceClient) GetReservationHistory(ctx context.Context, in *GetReservationByIDRequest, opts ...grpc.CallOption) (*ReservationHistory, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReservationHistory)
+	err := c.cc.Invoke(ctx, ReservationService_GetReservationHistory_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *reservationServiceClient) GetGuestReliability(ctx context.Context, in *GetGuestReliabilityRequest, opts ...grpc.CallOption) (*GuestReliability, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GuestReliability)
+	err := c.cc.Invoke(ctx, ReservationService_GetGuestReliability_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *reservationServiceClient) ConfirmDepositPayment(ctx context.Context, in *GetReservationByIDRequest, opts ...grpc.CallOption) (*Response, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Response)
+	err := c.cc.Invoke(ctx, ReservationService_ConfirmDepositPayment_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *reservationServiceClient) SearchReservations(ctx context.Context, in *SearchReservationsRequest, opts ...grpc.CallOption) (*Reservations, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Reservations)
+	err := c.cc.Invoke(ctx, ReservationService_SearchReservations_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *reservationServiceClient) GetReservationByCode(ctx context.Context, in *ReservationCodeRequest, opts ...grpc.CallOption) (*Reservation, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Reservation)
+	err := c.cc.Invoke(ctx, ReservationService_GetReservationByCode_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *reservationServiceClient) UpdateReservationByCode(ctx context.Context, in *UpdateReservationByCodeRequest, opts ...grpc.CallOption) (*Response, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Response)
+	err := c.cc.Invoke(ctx, ReservationService_UpdateReservationByCode_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *reservationServiceClient) CancelReservationByCode(ctx context.Context, in *ReservationCodeRequest, opts ...grpc.CallOption) (*Response, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Response)
+	err := c.cc.Invoke(ctx, ReservationService_CancelReservationByCode_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ReservationServiceServer is the server API for ReservationService service.
 // All implementations must embed UnimplementedReservationServiceServer
 // for forward compatibility.
@@ -117,6 +273,19 @@ type ReservationServiceServer interface {
 	GetReservationsByDate(context.Context, *GetReservationsByDateRequest) (*Reservations, error)
 	UpdateReservation(context.Context, *UpdateReservationRequest) (*Response, error)
 	DeleteReservation(context.Context, *DeleteReservationRequest) (*Response, error)
+	WalkIn(context.Context, *WalkInRequest) (*WalkInResponse, error)
+	HoldSlot(context.Context, *HoldSlotRequest) (*HoldSlotResponse, error)
+	CreateReservationSeries(context.Context, *CreateReservationSeriesRequest) (*ReservationSeriesResponse, error)
+	UpdateReservationSeries(context.Context, *UpdateReservationSeriesRequest) (*ReservationSeriesResponse, error)
+	CancelReservationSeries(context.Context, *CancelReservationSeriesRequest) (*ReservationSeriesResponse, error)
+	RestoreReservation(context.Context, *RestoreReservationRequest) (*Response, error)
+	GetReservationHistory(context.Context, *GetReservationByIDRequest) (*ReservationHistory, error)
+	GetGuestReliability(context.Context, *GetGuestReliabilityRequest) (*GuestReliability, error)
+	ConfirmDepositPayment(context.Context, *GetReservationByIDRequest) (*Response, error)
+	SearchReservations(context.Context, *SearchReservationsRequest) (*Reservations, error)
+	GetReservationByCode(context.Context, *ReservationCodeRequest) (*Reservation, error)
+	UpdateReservationByCode(context.Context, *UpdateReservationByCodeRequest) (*Response, error)
+	CancelReservationByCode(context.Context, *ReservationCodeRequest) (*Response, error)
 	mustEmbedUnimplementedReservationServiceServer()
 }
 
@@ -145,6 +314,45 @@ func (UnimplementedReservationServiceServer) UpdateReservation(context.Context,
 func (UnimplementedReservationServiceServer) DeleteReservation(context.Context, *DeleteReservationRequest) (*Response, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DeleteReservation not implemented")
 }
+func (UnimplementedReservationServiceServer) WalkIn(context.Context, *WalkInRequest) (*WalkInResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method WalkIn not implemented")
+}
+func (UnimplementedReservationServiceServer) HoldSlot(context.Context, *HoldSlotRequest) (*HoldSlotResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method HoldSlot not implemented")
+}
+func (UnimplementedReservationServiceServer) CreateReservationSeries(context.Context, *CreateReservationSeriesRequest) (*ReservationSeriesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateReservationSeries not implemented")
+}
+func (UnimplementedReservationServiceServer) UpdateReservationSeries(context.Context, *UpdateReservationSeriesRequest) (*ReservationSeriesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateReservationSeries not implemented")
+}
+func (UnimplementedReservationServiceServer) CancelReservationSeries(context.Context, *CancelReservationSeriesRequest) (*ReservationSeriesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelReservationSeries not implemented")
+}
+func (UnimplementedReservationServiceServer) RestoreReservation(context.Context, *RestoreReservationRequest) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestoreReservation not implemented")
+}
+func (UnimplementedReservationServiceServer) GetReservationHistory(context.Context, *GetReservationByIDRequest) (*ReservationHistory, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetReservationHistory not implemented")
+}
+func (UnimplementedReservationServiceServer) GetGuestReliability(context.Context, *GetGuestReliabilityRequest) (*GuestReliability, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetGuestReliability not implemented")
+}
+func (UnimplementedReservationServiceServer) ConfirmDepositPayment(context.Context, *GetReservationByIDRequest) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ConfirmDepositPayment not implemented")
+}
+func (UnimplementedReservationServiceServer) SearchReservations(context.Context, *SearchReservationsRequest) (*Reservations, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SearchReservations not implemented")
+}
+func (UnimplementedReservationServiceServer) GetReservationByCode(context.Context, *ReservationCodeRequest) (*Reservation, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetReservationByCode not implemented")
+}
+func (UnimplementedReservationServiceServer) UpdateReservationByCode(context.Context, *UpdateReservationByCodeRequest) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateReservationByCode not implemented")
+}
+func (UnimplementedReservationServiceServer) CancelReservationByCode(context.Context, *ReservationCodeRequest) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelReservationByCode not implemented")
+}
 func (UnimplementedReservationServiceServer) mustEmbedUnimplementedReservationServiceServer() {}
 func (UnimplementedReservationServiceServer) testEmbeddedByValue()                            {}
 
@@ -274,117 +482,403 @@ func _ReservationService_DeleteReservation_Handler(srv interface{}, ctx context.
 	return interceptor(ctx, in, info, handler)
 }
 
-// ReservationService_ServiceDesc is the grpc.ServiceDesc for ReservationService service.
-// It's only intended for direct use with grpc.RegisterService,
-// and not to be introspected or modified (even as a copy)
-var ReservationService_ServiceDesc = grpc.ServiceDesc{
-	ServiceName: "reservation.ReservationService",
-	HandlerType: (*ReservationServiceServer)(nil),
-	Methods: []grpc.MethodDesc{
-		{
-			MethodName: "CreateReservation",
-			Handler:    _ReservationService_CreateReservation_Handler,
-		},
-		{
-			MethodName: "GetReservationByID",
-			Handler:    _ReservationService_GetReservationByID_Handler,
-		},
-		{
-			MethodName: "GetReservationsByUserID",
-			Handler:    _ReservationService_GetReservationsByUserID_Handler,
-		},
-		{
-			MethodName: "GetReservationsByDate",
-			Handler:    _ReservationService_GetReservationsByDate_Handler,
-		},
-		{
-			MethodName: "UpdateReservation",
-			Handler:    _ReservationService_UpdateReservation_Handler,
-		},
-		{
-			MethodName: "DeleteReservation",
-			Handler:    _ReservationService_DeleteReservation_Handler,
-		},
-	},
-	Streams:  []grpc.StreamDesc{},
-	Metadata: "protos/protos/reservation.proto",
-}
-
-const (
-	TableService_CreateTable_FullMethodName        = "/reservation.TableService/CreateTable"
-	TableService_GetTables_FullMethodName          = "/reservation.TableService/GetTables"
-	TableService_UpdateTable_FullMethodName        = "/reservation.TableService/UpdateTable"
-	TableService_GetAvailableTables_FullMethodName = "/reservation.TableService/GetAvailableTables"
-)
-
-// TableServiceClient is the client API for TableService service.
-//
-// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
-type TableServiceClient interface {
-	CreateTable(ctx context.Context, in *CreateTableRequest, opts ...grpc.CallOption) (*Response, error)
-	GetTables(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Tables, error)
-	UpdateTable(ctx context.Context, in *UpdateTableRequest, opts ...grpc.CallOption) (*Response, error)
-	GetAvailableTables(ctx context.Context, in *GetAvailableTablesRequest, opts ...grpc.CallOption) (*Tables, error)
-}
-
-type tableServiceClient struct {
-	cc grpc.ClientConnInterface
+func _ReservationService_WalkIn_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WalkInRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReservationServiceServer).WalkIn(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReservationService_WalkIn_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReservationServiceServer).WalkIn(ctx, req.(*WalkInRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func NewTableServiceClient(cc grpc.ClientConnInterface) TableServiceClient {
-	return &tableServiceClient{cc}
+func _ReservationService_HoldSlot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HoldSlotRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReservationServiceServer).HoldSlot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReservationService_HoldSlot_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReservationServiceServer).HoldSlot(ctx, req.(*HoldSlotRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func (c *tableServiceClient) CreateTable(ctx context.Context, in *CreateTableRequest, opts ...grpc.CallOption) (*Response, error) {
-	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
-	out := new(Response)
-	err := c.cc.Invoke(ctx, TableService_CreateTable_FullMethodName, in, out, cOpts...)
-	if err != nil {
+func _ReservationService_CreateReservationSeries_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateReservationSeriesRequest)
+	if err := dec(in); err != nil {
 		return nil, err
 	}
-	return out, nil
+	if interceptor == nil {
+		return srv.(ReservationServiceServer).CreateReservationSeries(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReservationService_CreateReservationSeries_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReservationServiceServer).CreateReservationSeries(ctx, req.(*CreateReservationSeriesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func (c *tableServiceClient) GetTables(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Tables, error) {
-	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
-	out := new(Tables)
-	err := c.cc.Invoke(ctx, TableService_GetTables_FullMethodName, in, out, cOpts...)
-	if err != nil {
+func _ReservationService_UpdateReservationSeries_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateReservationSeriesRequest)
+	if err := dec(in); err != nil {
 		return nil, err
 	}
-	return out, nil
+	if interceptor == nil {
+		return srv.(ReservationServiceServer).UpdateReservationSeries(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReservationService_UpdateReservationSeries_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReservationServiceServer).UpdateReservationSeries(ctx, req.(*UpdateReservationSeriesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func (c *tableServiceClient) UpdateTable(ctx context.Context, in *UpdateTableRequest, opts ...grpc.CallOption) (*Response, error) {
-	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
-	out := new(Response)
-	err := c.cc.Invoke(ctx, TableService_UpdateTable_FullMethodName, in, out, cOpts...)
-	if err != nil {
+func _ReservationService_CancelReservationSeries_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelReservationSeriesRequest)
+	if err := dec(in); err != nil {
 		return nil, err
 	}
-	return out, nil
+	if interceptor == nil {
+		return srv.(ReservationServiceServer).CancelReservationSeries(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReservationService_CancelReservationSeries_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReservationServiceServer).CancelReservationSeries(ctx, req.(*CancelReservationSeriesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func (c *tableServiceClient) GetAvailableTables(ctx context.Context, in *GetAvailableTablesRequest, opts ...grpc.CallOption) (*Tables, error) {
-	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
-	out := new(Tables)
-	err := c.cc.Invoke(ctx, TableService_GetAvailableTables_FullMethodName, in, out, cOpts...)
-	if err != nil {
+func _ReservationService_RestoreReservation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestoreReservationRequest)
+	if err := dec(in); err != nil {
 		return nil, err
 	}
-	return out, nil
+	if interceptor == nil {
+		return srv.(ReservationServiceServer).RestoreReservation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReservationService_RestoreReservation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReservationServiceServer).RestoreReservation(ctx, req.(*RestoreReservationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-// TableServiceServer is the server API for TableService service.
-// All implementations must embed UnimplementedTableServiceServer
-// for forward compatibility.
-type TableServiceServer interface {
-	CreateTable(context.Context, *CreateTableRequest) (*Response, error)
-	GetTables(context.Context, *Empty) (*Tables, error)
-	UpdateTable(context.Context, *UpdateTableRequest) (*Response, error)
-	GetAvailableTables(context.Context, *GetAvailableTablesRequest) (*Tables, error)
-	mustEmbedUnimplementedTableServiceServer()
-}
+func _ReservationService_GetReservationHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetReservationByIDRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReservationServiceServer).GetReservationHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReservationService_GetReservationHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReservationServiceServer).GetReservationHistory(ctx, req.(*GetReservationByIDRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReservationService_GetGuestReliability_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetGuestReliabilityRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReservationServiceServer).GetGuestReliability(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReservationService_GetGuestReliability_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReservationServiceServer).GetGuestReliability(ctx, req.(*GetGuestReliabilityRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReservationService_ConfirmDepositPayment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetReservationByIDRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReservationServiceServer).ConfirmDepositPayment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReservationService_ConfirmDepositPayment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReservationServiceServer).ConfirmDepositPayment(ctx, req.(*GetReservationByIDRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReservationService_SearchReservations_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchReservationsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReservationServiceServer).SearchReservations(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReservationService_SearchReservations_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReservationServiceServer).SearchReservations(ctx, req.(*SearchReservationsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReservationService_GetReservationByCode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReservationCodeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReservationServiceServer).GetReservationByCode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReservationService_GetReservationByCode_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReservationServiceServer).GetReservationByCode(ctx, req.(*ReservationCodeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReservationService_UpdateReservationByCode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateReservationByCodeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReservationServiceServer).UpdateReservationByCode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReservationService_UpdateReservationByCode_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReservationServiceServer).UpdateReservationByCode(ctx, req.(*UpdateReservationByCodeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReservationService_CancelReservationByCode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReservationCodeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReservationServiceServer).CancelReservationByCode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReservationService_CancelReservationByCode_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReservationServiceServer).CancelReservationByCode(ctx, req.(*ReservationCodeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ReservationService_ServiceDesc is the grpc.ServiceDesc for ReservationService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ReservationService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "reservation.ReservationService",
+	HandlerType: (*ReservationServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateReservation",
+			Handler:    _ReservationService_CreateReservation_Handler,
+		},
+		{
+			MethodName: "GetReservationByID",
+			Handler:    _ReservationService_GetReservationByID_Handler,
+		},
+		{
+			MethodName: "GetReservationsByUserID",
+			Handler:    _ReservationService_GetReservationsByUserID_Handler,
+		},
+		{
+			MethodName: "GetReservationsByDate",
+			Handler:    _ReservationService_GetReservationsByDate_Handler,
+		},
+		{
+			MethodName: "UpdateReservation",
+			Handler:    _ReservationService_UpdateReservation_Handler,
+		},
+		{
+			MethodName: "DeleteReservation",
+			Handler:    _ReservationService_DeleteReservation_Handler,
+		},
+		{
+			MethodName: "WalkIn",
+			Handler:    _ReservationService_WalkIn_Handler,
+		},
+		{
+			MethodName: "HoldSlot",
+			Handler:    _ReservationService_HoldSlot_Handler,
+		},
+		{
+			MethodName: "CreateReservationSeries",
+			Handler:    _ReservationService_CreateReservationSeries_Handler,
+		},
+		{
+			MethodName: "UpdateReservationSeries",
+			Handler:    _ReservationService_UpdateReservationSeries_Handler,
+		},
+		{
+			MethodName: "CancelReservationSeries",
+			Handler:    _ReservationService_CancelReservationSeries_Handler,
+		},
+		{
+			MethodName: "RestoreReservation",
+			Handler:    _ReservationService_RestoreReservation_Handler,
+		},
+		{
+			MethodName: "GetReservationHistory",
+			Handler:    _ReservationService_GetReservationHistory_Handler,
+		},
+		{
+			MethodName: "GetGuestReliability",
+			Handler:    _ReservationService_GetGuestReliability_Handler,
+		},
+		{
+			MethodName: "ConfirmDepositPayment",
+			Handler:    _ReservationService_ConfirmDepositPayment_Handler,
+		},
+		{
+			MethodName: "SearchReservations",
+			Handler:    _ReservationService_SearchReservations_Handler,
+		},
+		{
+			MethodName: "GetReservationByCode",
+			Handler:    _ReservationService_GetReservationByCode_Handler,
+		},
+		{
+			MethodName: "UpdateReservationByCode",
+			Handler:    _ReservationService_UpdateReservationByCode_Handler,
+		},
+		{
+			MethodName: "CancelReservationByCode",
+			Handler:    _ReservationService_CancelReservationByCode_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "protos/protos/reservation.proto",
+}
+
+const (
+	TableService_CreateTable_FullMethodName        = "/reservation.TableService/CreateTable"
+	TableService_GetTables_FullMethodName          = "/reservation.TableService/GetTables"
+	TableService_UpdateTable_FullMethodName        = "/reservation.TableService/UpdateTable"
+	TableService_GetAvailableTables_FullMethodName = "/reservation.TableService/GetAvailableTables"
+)
+
+// TableServiceClient is the client API for TableService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type TableServiceClient interface {
+	CreateTable(ctx context.Context, in *CreateTableRequest, opts ...grpc.CallOption) (*Response, error)
+	GetTables(ctx context.Context, in *GetTablesRequest, opts ...grpc.CallOption) (*Tables, error)
+	UpdateTable(ctx context.Context, in *UpdateTableRequest, opts ...grpc.CallOption) (*Response, error)
+	GetAvailableTables(ctx context.Context, in *GetAvailableTablesRequest, opts ...grpc.CallOption) (*Tables, error)
+}
+
+type tableServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewTableServiceClient(cc grpc.ClientConnInterface) TableServiceClient {
+	return &tableServiceClient{cc}
+}
+
+func (c *tableServiceClient) CreateTable(ctx context.Context, in *CreateTableRequest, opts ...grpc.CallOption) (*Response, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Response)
+	err := c.cc.Invoke(ctx, TableService_CreateTable_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tableServiceClient) GetTables(ctx context.Context, in *GetTablesRequest, opts ...grpc.CallOption) (*Tables, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Tables)
+	err := c.cc.Invoke(ctx, TableService_GetTables_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tableServiceClient) UpdateTable(ctx context.Context, in *UpdateTableRequest, opts ...grpc.CallOption) (*Response, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Response)
+	err := c.cc.Invoke(ctx, TableService_UpdateTable_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tableServiceClient) GetAvailableTables(ctx context.Context, in *GetAvailableTablesRequest, opts ...grpc.CallOption) (*Tables, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Tables)
+	err := c.cc.Invoke(ctx, TableService_GetAvailableTables_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// TableServiceServer is the server API for TableService service.
+// All implementations must embed UnimplementedTableServiceServer
+// for forward compatibility.
+type TableServiceServer interface {
+	CreateTable(context.Context, *CreateTableRequest) (*Response, error)
+	GetTables(context.Context, *GetTablesRequest) (*Tables, error)
+	UpdateTable(context.Context, *UpdateTableRequest) (*Response, error)
+	GetAvailableTables(context.Context, *GetAvailableTablesRequest) (*Tables, error)
+	mustEmbedUnimplementedTableServiceServer()
+}
 
 // UnimplementedTableServiceServer must be embedded to have
 // forward compatible implementations.
@@ -396,7 +890,7 @@ type UnimplementedTableServiceServer struct{}
 func (UnimplementedTableServiceServer) CreateTable(context.Context, *CreateTableRequest) (*Response, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CreateTable not implemented")
 }
-func (UnimplementedTableServiceServer) GetTables(context.Context, *Empty) (*Tables, error) {
+func (UnimplementedTableServiceServer) GetTables(context.Context, *GetTablesRequest) (*Tables, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetTables not implemented")
 }
 func (UnimplementedTableServiceServer) UpdateTable(context.Context, *UpdateTableRequest) (*Response, error) {
@@ -445,7 +939,7 @@ func _TableService_CreateTable_Handler(srv interface{}, ctx context.Context, dec
 }
 
 func _TableService_GetTables_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(Empty)
+	in := new(GetTablesRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
@@ -457,7 +951,7 @@ func _TableService_GetTables_Handler(srv interface{}, ctx context.Context, dec f
 		FullMethod: TableService_GetTables_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(TableServiceServer).GetTables(ctx, req.(*Empty))
+		return srv.(TableServiceServer).GetTables(ctx, req.(*GetTablesRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
@@ -525,3 +1019,893 @@ var TableService_ServiceDesc = grpc.ServiceDesc{
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "protos/protos/reservation.proto",
 }
+
+const (
+	WaitlistService_JoinWaitlist_FullMethodName        = "/reservation.WaitlistService/JoinWaitlist"
+	WaitlistService_GetWaitlist_FullMethodName         = "/reservation.WaitlistService/GetWaitlist"
+	WaitlistService_AcceptWaitlistOffer_FullMethodName = "/reservation.WaitlistService/AcceptWaitlistOffer"
+	WaitlistService_LeaveWaitlist_FullMethodName       = "/reservation.WaitlistService/LeaveWaitlist"
+)
+
+// WaitlistServiceClient is the client API for WaitlistService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type WaitlistServiceClient interface {
+	JoinWaitlist(ctx context.Context, in *JoinWaitlistRequest, opts ...grpc.CallOption) (*WaitlistEntry, error)
+	GetWaitlist(ctx context.Context, in *GetWaitlistRequest, opts ...grpc.CallOption) (*WaitlistEntries, error)
+	AcceptWaitlistOffer(ctx context.Context, in *WaitlistEntryRequest, opts ...grpc.CallOption) (*Response, error)
+	LeaveWaitlist(ctx context.Context, in *WaitlistEntryRequest, opts ...grpc.CallOption) (*Response, error)
+}
+
+type waitlistServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewWaitlistServiceClient(cc grpc.ClientConnInterface) WaitlistServiceClient {
+	return &waitlistServiceClient{cc}
+}
+
+func (c *waitlistServiceClient) JoinWaitlist(ctx context.Context, in *JoinWaitlistRequest, opts ...grpc.CallOption) (*WaitlistEntry, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(WaitlistEntry)
+	err := c.cc.Invoke(ctx, WaitlistService_JoinWaitlist_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *waitlistServiceClient) GetWaitlist(ctx context.Context, in *GetWaitlistRequest, opts ...grpc.CallOption) (*WaitlistEntries, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(WaitlistEntries)
+	err := c.cc.Invoke(ctx, WaitlistService_GetWaitlist_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *waitlistServiceClient) AcceptWaitlistOffer(ctx context.Context, in *WaitlistEntryRequest, opts ...grpc.CallOption) (*Response, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Response)
+	err := c.cc.Invoke(ctx, WaitlistService_AcceptWaitlistOffer_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *waitlistServiceClient) LeaveWaitlist(ctx context.Context, in *WaitlistEntryRequest, opts ...grpc.CallOption) (*Response, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Response)
+	err := c.cc.Invoke(ctx, WaitlistService_LeaveWaitlist_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// WaitlistServiceServer is the server API for WaitlistService service.
+// All implementations must embed UnimplementedWaitlistServiceServer
+// for forward compatibility.
+type WaitlistServiceServer interface {
+	JoinWaitlist(context.Context, *JoinWaitlistRequest) (*WaitlistEntry, error)
+	GetWaitlist(context.Context, *GetWaitlistRequest) (*WaitlistEntries, error)
+	AcceptWaitlistOffer(context.Context, *WaitlistEntryRequest) (*Response, error)
+	LeaveWaitlist(context.Context, *WaitlistEntryRequest) (*Response, error)
+	mustEmbedUnimplementedWaitlistServiceServer()
+}
+
+// UnimplementedWaitlistServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedWaitlistServiceServer struct{}
+
+func (UnimplementedWaitlistServiceServer) JoinWaitlist(context.Context, *JoinWaitlistRequest) (*WaitlistEntry, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method JoinWaitlist not implemented")
+}
+func (UnimplementedWaitlistServiceServer) GetWaitlist(context.Context, *GetWaitlistRequest) (*WaitlistEntries, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetWaitlist not implemented")
+}
+func (UnimplementedWaitlistServiceServer) AcceptWaitlistOffer(context.Context, *WaitlistEntryRequest) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AcceptWaitlistOffer not implemented")
+}
+func (UnimplementedWaitlistServiceServer) LeaveWaitlist(context.Context, *WaitlistEntryRequest) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method LeaveWaitlist not implemented")
+}
+func (UnimplementedWaitlistServiceServer) mustEmbedUnimplementedWaitlistServiceServer() {}
+func (UnimplementedWaitlistServiceServer) testEmbeddedByValue()                         {}
+
+// UnsafeWaitlistServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to WaitlistServiceServer will
+// result in compilation errors.
+type UnsafeWaitlistServiceServer interface {
+	mustEmbedUnimplementedWaitlistServiceServer()
+}
+
+func RegisterWaitlistServiceServer(s grpc.ServiceRegistrar, srv WaitlistServiceServer) {
+	// If the following call pancis, it indicates UnimplementedWaitlistServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&WaitlistService_ServiceDesc, srv)
+}
+
+func _WaitlistService_JoinWaitlist_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(JoinWaitlistRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WaitlistServiceServer).JoinWaitlist(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WaitlistService_JoinWaitlist_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WaitlistServiceServer).JoinWaitlist(ctx, req.(*JoinWaitlistRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WaitlistService_GetWaitlist_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetWaitlistRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WaitlistServiceServer).GetWaitlist(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WaitlistService_GetWaitlist_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WaitlistServiceServer).GetWaitlist(ctx, req.(*GetWaitlistRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WaitlistService_AcceptWaitlistOffer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WaitlistEntryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WaitlistServiceServer).AcceptWaitlistOffer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WaitlistService_AcceptWaitlistOffer_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WaitlistServiceServer).AcceptWaitlistOffer(ctx, req.(*WaitlistEntryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WaitlistService_LeaveWaitlist_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WaitlistEntryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WaitlistServiceServer).LeaveWaitlist(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WaitlistService_LeaveWaitlist_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WaitlistServiceServer).LeaveWaitlist(ctx, req.(*WaitlistEntryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// WaitlistService_ServiceDesc is the grpc.ServiceDesc for WaitlistService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var WaitlistService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "reservation.WaitlistService",
+	HandlerType: (*WaitlistServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "JoinWaitlist",
+			Handler:    _WaitlistService_JoinWaitlist_Handler,
+		},
+		{
+			MethodName: "GetWaitlist",
+			Handler:    _WaitlistService_GetWaitlist_Handler,
+		},
+		{
+			MethodName: "AcceptWaitlistOffer",
+			Handler:    _WaitlistService_AcceptWaitlistOffer_Handler,
+		},
+		{
+			MethodName: "LeaveWaitlist",
+			Handler:    _WaitlistService_LeaveWaitlist_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "protos/protos/reservation.proto",
+}
+
+const (
+	RestaurantService_CreateRestaurant_FullMethodName  = "/reservation.RestaurantService/CreateRestaurant"
+	RestaurantService_GetRestaurants_FullMethodName    = "/reservation.RestaurantService/GetRestaurants"
+	RestaurantService_GetRestaurantByID_FullMethodName = "/reservation.RestaurantService/GetRestaurantByID"
+	RestaurantService_UpdateRestaurant_FullMethodName  = "/reservation.RestaurantService/UpdateRestaurant"
+)
+
+// RestaurantServiceClient is the client API for RestaurantService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type RestaurantServiceClient interface {
+	CreateRestaurant(ctx context.Context, in *CreateRestaurantRequest, opts ...grpc.CallOption) (*Response, error)
+	GetRestaurants(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Restaurants, error)
+	GetRestaurantByID(ctx context.Context, in *GetRestaurantByIDRequest, opts ...grpc.CallOption) (*Restaurant, error)
+	UpdateRestaurant(ctx context.Context, in *UpdateRestaurantRequest, opts ...grpc.CallOption) (*Response, error)
+}
+
+type restaurantServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewRestaurantServiceClient(cc grpc.ClientConnInterface) RestaurantServiceClient {
+	return &restaurantServiceClient{cc}
+}
+
+func (c *restaurantServiceClient) CreateRestaurant(ctx context.Context, in *CreateRestaurantRequest, opts ...grpc.CallOption) (*Response, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Response)
+	err := c.cc.Invoke(ctx, RestaurantService_CreateRestaurant_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *restaurantServiceClient) GetRestaurants(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Restaurants, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Restaurants)
+	err := c.cc.Invoke(ctx, RestaurantService_GetRestaurants_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *restaurantServiceClient) GetRestaurantByID(ctx context.Context, in *GetRestaurantByIDRequest, opts ...grpc.CallOption) (*Restaurant, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Restaurant)
+	err := c.cc.Invoke(ctx, RestaurantService_GetRestaurantByID_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *restaurantServiceClient) UpdateRestaurant(ctx context.Context, in *UpdateRestaurantRequest, opts ...grpc.CallOption) (*Response, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Response)
+	err := c.cc.Invoke(ctx, RestaurantService_UpdateRestaurant_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// RestaurantServiceServer is the server API for RestaurantService service.
+// All implementations must embed UnimplementedRestaurantServiceServer
+// for forward compatibility.
+type RestaurantServiceServer interface {
+	CreateRestaurant(context.Context, *CreateRestaurantRequest) (*Response, error)
+	GetRestaurants(context.Context, *Empty) (*Restaurants, error)
+	GetRestaurantByID(context.Context, *GetRestaurantByIDRequest) (*Restaurant, error)
+	UpdateRestaurant(context.Context, *UpdateRestaurantRequest) (*Response, error)
+	mustEmbedUnimplementedRestaurantServiceServer()
+}
+
+// UnimplementedRestaurantServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedRestaurantServiceServer struct{}
+
+func (UnimplementedRestaurantServiceServer) CreateRestaurant(context.Context, *CreateRestaurantRequest) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateRestaurant not implemented")
+}
+func (UnimplementedRestaurantServiceServer) GetRestaurants(context.Context, *Empty) (*Restaurants, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRestaurants not implemented")
+}
+func (UnimplementedRestaurantServiceServer) GetRestaurantByID(context.Context, *GetRestaurantByIDRequest) (*Restaurant, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRestaurantByID not implemented")
+}
+func (UnimplementedRestaurantServiceServer) UpdateRestaurant(context.Context, *UpdateRestaurantRequest) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateRestaurant not implemented")
+}
+func (UnimplementedRestaurantServiceServer) mustEmbedUnimplementedRestaurantServiceServer() {}
+func (UnimplementedRestaurantServiceServer) testEmbeddedByValue()                           {}
+
+// UnsafeRestaurantServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to RestaurantServiceServer will
+// result in compilation errors.
+type UnsafeRestaurantServiceServer interface {
+	mustEmbedUnimplementedRestaurantServiceServer()
+}
+
+func RegisterRestaurantServiceServer(s grpc.ServiceRegistrar, srv RestaurantServiceServer) {
+	// If the following call pancis, it indicates UnimplementedRestaurantServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&RestaurantService_ServiceDesc, srv)
+}
+
+func _RestaurantService_CreateRestaurant_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateRestaurantRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RestaurantServiceServer).CreateRestaurant(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RestaurantService_CreateRestaurant_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RestaurantServiceServer).CreateRestaurant(ctx, req.(*CreateRestaurantRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RestaurantService_GetRestaurants_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RestaurantServiceServer).GetRestaurants(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RestaurantService_GetRestaurants_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RestaurantServiceServer).GetRestaurants(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RestaurantService_GetRestaurantByID_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRestaurantByIDRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RestaurantServiceServer).GetRestaurantByID(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RestaurantService_GetRestaurantByID_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RestaurantServiceServer).GetRestaurantByID(ctx, req.(*GetRestaurantByIDRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RestaurantService_UpdateRestaurant_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateRestaurantRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RestaurantServiceServer).UpdateRestaurant(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RestaurantService_UpdateRestaurant_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RestaurantServiceServer).UpdateRestaurant(ctx, req.(*UpdateRestaurantRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// RestaurantService_ServiceDesc is the grpc.ServiceDesc for RestaurantService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var RestaurantService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "reservation.RestaurantService",
+	HandlerType: (*RestaurantServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateRestaurant",
+			Handler:    _RestaurantService_CreateRestaurant_Handler,
+		},
+		{
+			MethodName: "GetRestaurants",
+			Handler:    _RestaurantService_GetRestaurants_Handler,
+		},
+		{
+			MethodName: "GetRestaurantByID",
+			Handler:    _RestaurantService_GetRestaurantByID_Handler,
+		},
+		{
+			MethodName: "UpdateRestaurant",
+			Handler:    _RestaurantService_UpdateRestaurant_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "protos/protos/reservation.proto",
+}
+
+const (
+	AuditService_GetAuditHistory_FullMethodName = "/reservation.AuditService/GetAuditHistory"
+)
+
+// AuditServiceClient is the client API for AuditService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type AuditServiceClient interface {
+	GetAuditHistory(ctx context.Context, in *GetAuditHistoryRequest, opts ...grpc.CallOption) (*AuditHistory, error)
+}
+
+type auditServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewAuditServiceClient(cc grpc.ClientConnInterface) AuditServiceClient {
+	return &auditServiceClient{cc}
+}
+
+func (c *auditServiceClient) GetAuditHistory(ctx context.Context, in *GetAuditHistoryRequest, opts ...grpc.CallOption) (*AuditHistory, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AuditHistory)
+	err := c.cc.Invoke(ctx, AuditService_GetAuditHistory_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AuditServiceServer is the server API for AuditService service.
+// All implementations must embed UnimplementedAuditServiceServer
+// for forward compatibility.
+type AuditServiceServer interface {
+	GetAuditHistory(context.Context, *GetAuditHistoryRequest) (*AuditHistory, error)
+	mustEmbedUnimplementedAuditServiceServer()
+}
+
+// UnimplementedAuditServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedAuditServiceServer struct{}
+
+func (UnimplementedAuditServiceServer) GetAuditHistory(context.Context, *GetAuditHistoryRequest) (*AuditHistory, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAuditHistory not implemented")
+}
+func (UnimplementedAuditServiceServer) mustEmbedUnimplementedAuditServiceServer() {}
+func (UnimplementedAuditServiceServer) testEmbeddedByValue()                      {}
+
+// UnsafeAuditServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to AuditServiceServer will
+// result in compilation errors.
+type UnsafeAuditServiceServer interface {
+	mustEmbedUnimplementedAuditServiceServer()
+}
+
+func RegisterAuditServiceServer(s grpc.ServiceRegistrar, srv AuditServiceServer) {
+	// If the following call pancis, it indicates UnimplementedAuditServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&AuditService_ServiceDesc, srv)
+}
+
+func _AuditService_GetAuditHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetAuditHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuditServiceServer).GetAuditHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuditService_GetAuditHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuditServiceServer).GetAuditHistory(ctx, req.(*GetAuditHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// AuditService_ServiceDesc is the grpc.ServiceDesc for AuditService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var AuditService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "reservation.AuditService",
+	HandlerType: (*AuditServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetAuditHistory",
+			Handler:    _AuditService_GetAuditHistory_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "protos/protos/reservation.proto",
+}
+
+const (
+	WebhookService_RegisterWebhook_FullMethodName      = "/reservation.WebhookService/RegisterWebhook"
+	WebhookService_ListWebhooks_FullMethodName         = "/reservation.WebhookService/ListWebhooks"
+	WebhookService_DeleteWebhook_FullMethodName        = "/reservation.WebhookService/DeleteWebhook"
+	WebhookService_GetWebhookDeliveries_FullMethodName = "/reservation.WebhookService/GetWebhookDeliveries"
+)
+
+// WebhookServiceClient is the client API for WebhookService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type WebhookServiceClient interface {
+	RegisterWebhook(ctx context.Context, in *RegisterWebhookRequest, opts ...grpc.CallOption) (*Webhook, error)
+	ListWebhooks(ctx context.Context, in *ListWebhooksRequest, opts ...grpc.CallOption) (*Webhooks, error)
+	DeleteWebhook(ctx context.Context, in *WebhookRequest, opts ...grpc.CallOption) (*Response, error)
+	GetWebhookDeliveries(ctx context.Context, in *GetWebhookDeliveriesRequest, opts ...grpc.CallOption) (*WebhookDeliveries, error)
+}
+
+type webhookServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewWebhookServiceClient(cc grpc.ClientConnInterface) WebhookServiceClient {
+	return &webhookServiceClient{cc}
+}
+
+func (c *webhookServiceClient) RegisterWebhook(ctx context.Context, in *RegisterWebhookRequest, opts ...grpc.CallOption) (*Webhook, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Webhook)
+	err := c.cc.Invoke(ctx, WebhookService_RegisterWebhook_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *webhookServiceClient) ListWebhooks(ctx context.Context, in *ListWebhooksRequest, opts ...grpc.CallOption) (*Webhooks, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Webhooks)
+	err := c.cc.Invoke(ctx, WebhookService_ListWebhooks_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *webhookServiceClient) DeleteWebhook(ctx context.Context, in *WebhookRequest, opts ...grpc.CallOption) (*Response, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Response)
+	err := c.cc.Invoke(ctx, WebhookService_DeleteWebhook_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *webhookServiceClient) GetWebhookDeliveries(ctx context.Context, in *GetWebhookDeliveriesRequest, opts ...grpc.CallOption) (*WebhookDeliveries, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(WebhookDeliveries)
+	err := c.cc.Invoke(ctx, WebhookService_GetWebhookDeliveries_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// WebhookServiceServer is the server API for WebhookService service.
+// All implementations must embed UnimplementedWebhookServiceServer
+// for forward compatibility.
+type WebhookServiceServer interface {
+	RegisterWebhook(context.Context, *RegisterWebhookRequest) (*Webhook, error)
+	ListWebhooks(context.Context, *ListWebhooksRequest) (*Webhooks, error)
+	DeleteWebhook(context.Context, *WebhookRequest) (*Response, error)
+	GetWebhookDeliveries(context.Context, *GetWebhookDeliveriesRequest) (*WebhookDeliveries, error)
+	mustEmbedUnimplementedWebhookServiceServer()
+}
+
+// UnimplementedWebhookServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedWebhookServiceServer struct{}
+
+func (UnimplementedWebhookServiceServer) RegisterWebhook(context.Context, *RegisterWebhookRequest) (*Webhook, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RegisterWebhook not implemented")
+}
+func (UnimplementedWebhookServiceServer) ListWebhooks(context.Context, *ListWebhooksRequest) (*Webhooks, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListWebhooks not implemented")
+}
+func (UnimplementedWebhookServiceServer) DeleteWebhook(context.Context, *WebhookRequest) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteWebhook not implemented")
+}
+func (UnimplementedWebhookServiceServer) GetWebhookDeliveries(context.Context, *GetWebhookDeliveriesRequest) (*WebhookDeliveries, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetWebhookDeliveries not implemented")
+}
+func (UnimplementedWebhookServiceServer) mustEmbedUnimplementedWebhookServiceServer() {}
+func (UnimplementedWebhookServiceServer) testEmbeddedByValue()                        {}
+
+// UnsafeWebhookServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to WebhookServiceServer will
+// result in compilation errors.
+type UnsafeWebhookServiceServer interface {
+	mustEmbedUnimplementedWebhookServiceServer()
+}
+
+func RegisterWebhookServiceServer(s grpc.ServiceRegistrar, srv WebhookServiceServer) {
+	// If the following call pancis, it indicates UnimplementedWebhookServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&WebhookService_ServiceDesc, srv)
+}
+
+func _WebhookService_RegisterWebhook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RegisterWebhookRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WebhookServiceServer).RegisterWebhook(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WebhookService_RegisterWebhook_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WebhookServiceServer).RegisterWebhook(ctx, req.(*RegisterWebhookRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WebhookService_ListWebhooks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListWebhooksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WebhookServiceServer).ListWebhooks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WebhookService_ListWebhooks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WebhookServiceServer).ListWebhooks(ctx, req.(*ListWebhooksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WebhookService_DeleteWebhook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WebhookRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WebhookServiceServer).DeleteWebhook(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WebhookService_DeleteWebhook_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WebhookServiceServer).DeleteWebhook(ctx, req.(*WebhookRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WebhookService_GetWebhookDeliveries_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetWebhookDeliveriesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WebhookServiceServer).GetWebhookDeliveries(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WebhookService_GetWebhookDeliveries_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WebhookServiceServer).GetWebhookDeliveries(ctx, req.(*GetWebhookDeliveriesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// WebhookService_ServiceDesc is the grpc.ServiceDesc for WebhookService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var WebhookService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "reservation.WebhookService",
+	HandlerType: (*WebhookServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "RegisterWebhook",
+			Handler:    _WebhookService_RegisterWebhook_Handler,
+		},
+		{
+			MethodName: "ListWebhooks",
+			Handler:    _WebhookService_ListWebhooks_Handler,
+		},
+		{
+			MethodName: "DeleteWebhook",
+			Handler:    _WebhookService_DeleteWebhook_Handler,
+		},
+		{
+			MethodName: "GetWebhookDeliveries",
+			Handler:    _WebhookService_GetWebhookDeliveries_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "protos/protos/reservation.proto",
+}
+
+const (
+	GuestService_GetGuestProfile_FullMethodName    = "/reservation.GuestService/GetGuestProfile"
+	GuestService_UpdateGuestProfile_FullMethodName = "/reservation.GuestService/UpdateGuestProfile"
+)
+
+// GuestServiceClient is the client API for GuestService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type GuestServiceClient interface {
+	GetGuestProfile(ctx context.Context, in *GetGuestProfileRequest, opts ...grpc.CallOption) (*GuestProfile, error)
+	UpdateGuestProfile(ctx context.Context, in *UpdateGuestProfileRequest, opts ...grpc.CallOption) (*GuestProfile, error)
+}
+
+type guestServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewGuestServiceClient(cc grpc.ClientConnInterface) GuestServiceClient {
+	return &guestServiceClient{cc}
+}
+
+func (c *guestServiceClient) GetGuestProfile(ctx context.Context, in *GetGuestProfileRequest, opts ...grpc.CallOption) (*GuestProfile, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GuestProfile)
+	err := c.cc.Invoke(ctx, GuestService_GetGuestProfile_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *guestServiceClient) UpdateGuestProfile(ctx context.Context, in *UpdateGuestProfileRequest, opts ...grpc.CallOption) (*GuestProfile, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GuestProfile)
+	err := c.cc.Invoke(ctx, GuestService_UpdateGuestProfile_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GuestServiceServer is the server API for GuestService service.
+// All implementations must embed UnimplementedGuestServiceServer
+// for forward compatibility.
+type GuestServiceServer interface {
+	GetGuestProfile(context.Context, *GetGuestProfileRequest) (*GuestProfile, error)
+	UpdateGuestProfile(context.Context, *UpdateGuestProfileRequest) (*GuestProfile, error)
+	mustEmbedUnimplementedGuestServiceServer()
+}
+
+// UnimplementedGuestServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedGuestServiceServer struct{}
+
+func (UnimplementedGuestServiceServer) GetGuestProfile(context.Context, *GetGuestProfileRequest) (*GuestProfile, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetGuestProfile not implemented")
+}
+func (UnimplementedGuestServiceServer) UpdateGuestProfile(context.Context, *UpdateGuestProfileRequest) (*GuestProfile, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateGuestProfile not implemented")
+}
+func (UnimplementedGuestServiceServer) mustEmbedUnimplementedGuestServiceServer() {}
+func (UnimplementedGuestServiceServer) testEmbeddedByValue()                      {}
+
+// UnsafeGuestServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to GuestServiceServer will
+// result in compilation errors.
+type UnsafeGuestServiceServer interface {
+	mustEmbedUnimplementedGuestServiceServer()
+}
+
+func RegisterGuestServiceServer(s grpc.ServiceRegistrar, srv GuestServiceServer) {
+	// If the following call pancis, it indicates UnimplementedGuestServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&GuestService_ServiceDesc, srv)
+}
+
+func _GuestService_GetGuestProfile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetGuestProfileRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GuestServiceServer).GetGuestProfile(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GuestService_GetGuestProfile_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GuestServiceServer).GetGuestProfile(ctx, req.(*GetGuestProfileRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GuestService_UpdateGuestProfile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateGuestProfileRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GuestServiceServer).UpdateGuestProfile(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GuestService_UpdateGuestProfile_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GuestServiceServer).UpdateGuestProfile(ctx, req.(*UpdateGuestProfileRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// GuestService_ServiceDesc is the grpc.ServiceDesc for GuestService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var GuestService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "reservation.GuestService",
+	HandlerType: (*GuestServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetGuestProfile",
+			Handler:    _GuestService_GetGuestProfile_Handler,
+		},
+		{
+			MethodName: "UpdateGuestProfile",
+			Handler:    _GuestService_UpdateGuestProfile_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "protos/protos/reservation.proto",
+}
@@ -0,0 +1,79 @@
+package server
+
+import (
+	"context"
+	"strings"
+
+	"ms-reservas/auth"
+	"ms-reservas/logging"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// errMissingToken indica que la petición no trae credenciales, lo que solo
+// se admite en los métodos públicos de la política.
+var errMissingToken = status.Error(codes.Unauthenticated, "missing authorization header")
+
+// AuthUnaryInterceptor exige un token bearer válido en la cabecera
+// authorization, salvo en los métodos públicos de policy, y guarda la
+// identidad del llamante en el contexto.
+func AuthUnaryInterceptor(verifier *auth.Verifier, policy *auth.Policy) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		authenticated, err := authenticate(ctx, verifier)
+		if err == errMissingToken && policy.IsPublic(info.FullMethod) {
+			return handler(ctx, req)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return handler(authenticated, req)
+	}
+}
+
+// AuthStreamInterceptor es el equivalente de AuthUnaryInterceptor para
+// llamadas en streaming.
+func AuthStreamInterceptor(verifier *auth.Verifier, policy *auth.Policy) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticate(ss.Context(), verifier)
+		if err == errMissingToken && policy.IsPublic(info.FullMethod) {
+			return handler(srv, ss)
+		}
+		if err != nil {
+			return err
+		}
+		return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+func authenticate(ctx context.Context, verifier *auth.Verifier) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return nil, errMissingToken
+	}
+
+	scheme, token, found := strings.Cut(values[0], " ")
+	if !found || !strings.EqualFold(scheme, "bearer") || token == "" {
+		return nil, status.Error(codes.Unauthenticated, "authorization header must be a bearer token")
+	}
+
+	identity, err := verifier.Verify(token)
+	if err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "invalid token: %v", err)
+	}
+	logging.SetCaller(ctx, identity.Subject)
+	return auth.NewContext(ctx, identity), nil
+}
+
+// contextStream permite sustituir el contexto de un grpc.ServerStream.
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
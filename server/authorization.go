@@ -0,0 +1,181 @@
+package server
+
+import (
+	"context"
+	"slices"
+
+	"ms-reservas/auth"
+	"ms-reservas/controllers"
+	pb "ms-reservas/protos_pb/proto"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// AuthorizationUnaryInterceptor rechaza las llamadas cuyo llamante no tiene
+// ninguno de los roles que la política permite para el método. Debe ir
+// después de AuthUnaryInterceptor.
+func AuthorizationUnaryInterceptor(policy *auth.Policy) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := authorize(ctx, policy, info.FullMethod); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// AuthorizationStreamInterceptor es el equivalente de
+// AuthorizationUnaryInterceptor para llamadas en streaming.
+func AuthorizationStreamInterceptor(policy *auth.Policy) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := authorize(ss.Context(), policy, info.FullMethod); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+func authorize(ctx context.Context, policy *auth.Policy, method string) error {
+	if policy.IsPublic(method) {
+		return nil
+	}
+	identity, ok := auth.FromContext(ctx)
+	if !ok {
+		return status.Error(codes.Unauthenticated, "missing caller identity")
+	}
+	if !policy.Allowed(method, identity.Roles) {
+		return status.Errorf(codes.PermissionDenied, "role not allowed to call %s", method)
+	}
+	return nil
+}
+
+// requireOwner comprueba que un huésped solo actúa sobre sus propios datos.
+// El personal puede actuar sobre cualquier usuario. Sin identidad en el
+// contexto (autenticación desactivada) no se aplica ninguna restricción.
+func requireOwner(ctx context.Context, userID string) error {
+	identity, ok := auth.FromContext(ctx)
+	if !ok || identity.IsStaff() {
+		return nil
+	}
+	if userID != identity.Subject {
+		return status.Error(codes.PermissionDenied, "guests can only access their own reservations")
+	}
+	return nil
+}
+
+// requireStaff rechaza a los huéspedes. Sin identidad en el contexto no se
+// aplica ninguna restricción.
+func requireStaff(ctx context.Context, what string) error {
+	if identity, ok := auth.FromContext(ctx); ok && !identity.IsStaff() {
+		return status.Errorf(codes.PermissionDenied, "only staff can %s", what)
+	}
+	return nil
+}
+
+// requireAdmin rechaza a quien no sea administrador. Sin identidad en el
+// contexto no se aplica ninguna restricción.
+func requireAdmin(ctx context.Context, what string) error {
+	if identity, ok := auth.FromContext(ctx); ok && !slices.Contains(identity.Roles, auth.RoleAdmin) {
+		return status.Errorf(codes.PermissionDenied, "only admins can %s", what)
+	}
+	return nil
+}
+
+// guestCreateStatus devuelve el estado con el que se crea la reserva. Un
+// huésped solo puede pedir confirmada (por defecto) o por_confirmar; el
+// resto de estados, como sentada, los asigna el personal.
+func guestCreateStatus(ctx context.Context, reservationStatus string) (string, error) {
+	if identity, ok := auth.FromContext(ctx); !ok || identity.IsStaff() {
+		return reservationStatus, nil
+	}
+	switch reservationStatus {
+	case "":
+		return "confirmada", nil
+	case "confirmada", controllers.StatusPendingConfirmation:
+		return reservationStatus, nil
+	}
+	return "", status.Errorf(codes.PermissionDenied, "only staff can create reservations with status %s", reservationStatus)
+}
+
+// requireStatusChange reserva al personal los cambios de estado: un
+// huésped solo puede cancelar su reserva, y la cancelación pasa por la
+// política del restaurante. El resto (completada, no presentada...)
+// cuenta para la fiabilidad y las visitas del cliente o se salta la
+// política de cancelación y los depósitos.
+func requireStatusChange(ctx context.Context, reservationStatus string) error {
+	if reservationStatus == "" || reservationStatus == "cancelada" {
+		return nil
+	}
+	return requireStaff(ctx, "set the reservation status to "+reservationStatus)
+}
+
+// hideStaffData quita de las reservas que ve un huésped lo que solo usa el
+// personal: las notas internas y, del resumen del cliente, las etiquetas y
+// el recuento de visitas.
+func hideStaffData(ctx context.Context, reservations ...*pb.Reservation) {
+	if identity, ok := auth.FromContext(ctx); !ok || identity.IsStaff() {
+		return
+	}
+	for _, reservation := range reservations {
+		reservation.StaffNotes = ""
+		if guest := reservation.Guest; guest != nil {
+			guest.Tags = nil
+			guest.VisitCount = 0
+			guest.LastVisit = ""
+		}
+	}
+}
+
+// hideStaffProfileData quita del perfil que ve un huésped las etiquetas
+// que le asigna el personal y el recuento de visitas.
+func hideStaffProfileData(ctx context.Context, profile *pb.GuestProfile) {
+	if identity, ok := auth.FromContext(ctx); !ok || identity.IsStaff() {
+		return
+	}
+	profile.Tags = nil
+	profile.VisitCount = 0
+	profile.LastVisit = ""
+}
+
+// ownUserID devuelve el usuario sobre el que actúa la petición: si un
+// huésped no lo indica, se usa el sujeto de su token.
+func ownUserID(ctx context.Context, userID string) (string, error) {
+	if identity, ok := auth.FromContext(ctx); ok && userID == "" && !identity.IsStaff() {
+		return identity.Subject, nil
+	}
+	return userID, requireOwner(ctx, userID)
+}
+
+func requireReservationOwner(ctx context.Context, restaurantID, id string) error {
+	if identity, ok := auth.FromContext(ctx); !ok || identity.IsStaff() {
+		return nil
+	}
+	reservation, err := controllers.GetReservationByID(ctx, restaurantID, id)
+	if err != nil {
+		return status.Error(codes.NotFound, "reservation not found")
+	}
+	return requireOwner(ctx, reservation.UserId)
+}
+
+func requireSeriesOwner(ctx context.Context, seriesID string) error {
+	if identity, ok := auth.FromContext(ctx); !ok || identity.IsStaff() {
+		return nil
+	}
+	series, err := controllers.GetSeriesByID(ctx, seriesID)
+	if err != nil {
+		return status.Error(codes.NotFound, "reservation series not found")
+	}
+	return requireOwner(ctx, series.UserId)
+}
+
+func requireWaitlistEntryOwner(ctx context.Context, restaurantID, id string) error {
+	if identity, ok := auth.FromContext(ctx); !ok || identity.IsStaff() {
+		return nil
+	}
+	entry, err := controllers.GetWaitlistEntryByID(ctx, restaurantID, id)
+	if err != nil {
+		return status.Error(codes.NotFound, "waitlist entry not found")
+	}
+	return requireOwner(ctx, entry.UserId)
+}
@@ -2,52 +2,344 @@ package server
 
 import (
 	"context"
+	"errors"
 	"ms-reservas/controllers"
 	pb "ms-reservas/protos_pb/proto"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type Server struct {
 	pb.UnimplementedReservationServiceServer
 	pb.UnimplementedTableServiceServer
+	pb.UnimplementedWaitlistServiceServer
+	pb.UnimplementedRestaurantServiceServer
+	pb.UnimplementedAuditServiceServer
+	pb.UnimplementedWebhookServiceServer
+	pb.UnimplementedGuestServiceServer
 }
 
 func (s *Server) CreateReservation(ctx context.Context, req *pb.CreateReservationRequest) (*pb.Response, error) {
-	return controllers.CreateReservationHandler(req)
+	userID, err := ownUserID(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+	if req.StaffNotes != "" {
+		if err := requireStaff(ctx, "add staff notes"); err != nil {
+			return nil, err
+		}
+	}
+	req.UserId = userID
+	if req.Status, err = guestCreateStatus(ctx, req.Status); err != nil {
+		return nil, err
+	}
+	return controllers.CreateReservationHandler(ctx, req)
 }
 
 func (s *Server) GetReservationByID(ctx context.Context, req *pb.GetReservationByIDRequest) (*pb.Reservation, error) {
-	return controllers.GetByIdHandler(req)
+	if err := requireReservationOwner(ctx, req.RestaurantId, req.Id); err != nil {
+		return nil, err
+	}
+	reservation, err := controllers.GetByIdHandler(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	hideStaffData(ctx, reservation)
+	return reservation, nil
 }
 
 func (s *Server) GetReservationsByUserID(ctx context.Context, req *pb.GetReservationsByUserIDRequest) (*pb.Reservations, error) {
-	return controllers.GetReservationsByUserIDHandler(req)
+	userID, err := ownUserID(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+	req.UserId = userID
+	reservations, err := controllers.GetReservationsByUserIDHandler(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	hideStaffData(ctx, reservations.Reservations...)
+	return reservations, nil
 }
 
 func (s *Server) GetReservationsByDate(ctx context.Context, req *pb.GetReservationsByDateRequest) (*pb.Reservations, error) {
-	return controllers.GetReservationsByDateHandler(req)
+	return controllers.GetReservationsByDateHandler(ctx, req)
+}
+
+func (s *Server) SearchReservations(ctx context.Context, req *pb.SearchReservationsRequest) (*pb.Reservations, error) {
+	return controllers.SearchReservationsHandler(ctx, req)
 }
 
 func (s *Server) UpdateReservation(ctx context.Context, req *pb.UpdateReservationRequest) (*pb.Response, error) {
-	return controllers.UpdateReservationHandler(req)
+	if err := requireReservationOwner(ctx, req.RestaurantId, req.Id); err != nil {
+		return nil, err
+	}
+	if req.StaffNotes != "" {
+		if err := requireStaff(ctx, "add staff notes"); err != nil {
+			return nil, err
+		}
+	}
+	if err := requireStatusChange(ctx, req.Status); err != nil {
+		return nil, err
+	}
+	return reservationChecked(controllers.UpdateReservationHandler(ctx, req))
 }
 
 func (s *Server) DeleteReservation(ctx context.Context, req *pb.DeleteReservationRequest) (*pb.Response, error) {
-	return controllers.DeleteReservationHandler(req)
+	if err := requireReservationOwner(ctx, req.RestaurantId, req.Id); err != nil {
+		return nil, err
+	}
+	return reservationChecked(controllers.DeleteReservationHandler(ctx, req))
+}
+
+func (s *Server) RestoreReservation(ctx context.Context, req *pb.RestoreReservationRequest) (*pb.Response, error) {
+	return reservationChecked(controllers.RestoreReservationHandler(ctx, req))
+}
+
+func (s *Server) GetReservationHistory(ctx context.Context, req *pb.GetReservationByIDRequest) (*pb.ReservationHistory, error) {
+	if err := requireReservationOwner(ctx, req.RestaurantId, req.Id); err != nil {
+		return nil, err
+	}
+	return controllers.GetReservationHistoryHandler(ctx, req)
+}
+
+func (s *Server) ConfirmDepositPayment(ctx context.Context, req *pb.GetReservationByIDRequest) (*pb.Response, error) {
+	if err := requireReservationOwner(ctx, req.RestaurantId, req.Id); err != nil {
+		return nil, err
+	}
+	return depositChecked(controllers.ConfirmDepositPaymentHandler(ctx, req))
+}
+
+// depositChecked traduce los errores de la confirmación de un depósito: un
+// pago sin completar o una reserva que no espera pago a
+// codes.FailedPrecondition y los fallos del proveedor a codes.Unavailable,
+// para que el cliente sepa si reintentar.
+func depositChecked(resp *pb.Response, err error) (*pb.Response, error) {
+	switch {
+	case errors.Is(err, mongo.ErrNoDocuments):
+		return nil, status.Error(codes.NotFound, "reservation not found")
+	case errors.Is(err, controllers.ErrDepositNotPaid), errors.Is(err, controllers.ErrNotPendingPayment):
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, controllers.ErrDepositsUnavailable), errors.Is(err, controllers.ErrPaymentProvider):
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+	return reservationChecked(resp, err)
+}
+
+func (s *Server) GetGuestReliability(ctx context.Context, req *pb.GetGuestReliabilityRequest) (*pb.GuestReliability, error) {
+	userID, err := ownUserID(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+	req.UserId = userID
+	return controllers.GetGuestReliabilityHandler(ctx, req)
+}
+
+func (s *Server) GetGuestProfile(ctx context.Context, req *pb.GetGuestProfileRequest) (*pb.GuestProfile, error) {
+	userID, err := ownUserID(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+	req.UserId = userID
+	profile, err := controllers.GetGuestProfileHandler(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	hideStaffProfileData(ctx, profile)
+	return profile, nil
+}
+
+// UpdateGuestProfile permite al huésped mantener sus datos; las etiquetas
+// (VIP, habitual...) solo las asigna el personal.
+func (s *Server) UpdateGuestProfile(ctx context.Context, req *pb.UpdateGuestProfileRequest) (*pb.GuestProfile, error) {
+	userID, err := ownUserID(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+	if req.Tags != nil {
+		if err := requireStaff(ctx, "tag guests"); err != nil {
+			return nil, err
+		}
+	}
+	req.UserId = userID
+	profile, err := controllers.UpdateGuestProfileHandler(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	hideStaffProfileData(ctx, profile)
+	return profile, nil
+}
+
+// Los métodos por código de confirmación son públicos: el código junto con
+// el apellido o el teléfono del cliente sustituye al token.
+func (s *Server) GetReservationByCode(ctx context.Context, req *pb.ReservationCodeRequest) (*pb.Reservation, error) {
+	reservation, err := controllers.GetReservationByCodeHandler(ctx, req)
+	if errors.Is(err, controllers.ErrReservationCodeNotFound) {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	if errors.Is(err, controllers.ErrTooManyCodeAttempts) {
+		return nil, status.Error(codes.ResourceExhausted, err.Error())
+	}
+	return reservation, err
+}
+
+func (s *Server) UpdateReservationByCode(ctx context.Context, req *pb.UpdateReservationByCodeRequest) (*pb.Response, error) {
+	return reservationChecked(controllers.UpdateReservationByCodeHandler(ctx, req))
+}
+
+func (s *Server) CancelReservationByCode(ctx context.Context, req *pb.ReservationCodeRequest) (*pb.Response, error) {
+	return reservationChecked(controllers.CancelReservationByCodeHandler(ctx, req))
+}
+
+// reservationChecked traduce los conflictos de versión a codes.Aborted, para
+// que el cliente sepa que debe releer la reserva y reintentar, los rechazos
+// de la política de cancelación y del autoservicio a
+// codes.FailedPrecondition, los códigos de confirmación no válidos a
+// codes.NotFound y los bloqueados por intentos fallidos a
+// codes.ResourceExhausted.
+func reservationChecked(resp *pb.Response, err error) (*pb.Response, error) {
+	if errors.Is(err, controllers.ErrVersionConflict) {
+		return nil, status.Error(codes.Aborted, err.Error())
+	}
+	if errors.Is(err, controllers.ErrNotCancellable) || errors.Is(err, controllers.ErrNotModifiable) {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	if errors.Is(err, controllers.ErrReservationCodeNotFound) {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	if errors.Is(err, controllers.ErrTooManyCodeAttempts) {
+		return nil, status.Error(codes.ResourceExhausted, err.Error())
+	}
+	return resp, err
+}
+
+func (s *Server) WalkIn(ctx context.Context, req *pb.WalkInRequest) (*pb.WalkInResponse, error) {
+	return controllers.WalkInHandler(ctx, req)
+}
+
+func (s *Server) HoldSlot(ctx context.Context, req *pb.HoldSlotRequest) (*pb.HoldSlotResponse, error) {
+	userID, err := ownUserID(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+	req.UserId = userID
+	return controllers.HoldSlotHandler(ctx, req)
+}
+
+func (s *Server) CreateReservationSeries(ctx context.Context, req *pb.CreateReservationSeriesRequest) (*pb.ReservationSeriesResponse, error) {
+	userID, err := ownUserID(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+	req.UserId = userID
+	return controllers.CreateReservationSeriesHandler(ctx, req)
+}
+
+func (s *Server) UpdateReservationSeries(ctx context.Context, req *pb.UpdateReservationSeriesRequest) (*pb.ReservationSeriesResponse, error) {
+	if err := requireSeriesOwner(ctx, req.SeriesId); err != nil {
+		return nil, err
+	}
+	if err := requireStatusChange(ctx, req.Status); err != nil {
+		return nil, err
+	}
+	return controllers.UpdateReservationSeriesHandler(ctx, req)
+}
+
+func (s *Server) CancelReservationSeries(ctx context.Context, req *pb.CancelReservationSeriesRequest) (*pb.ReservationSeriesResponse, error) {
+	if err := requireSeriesOwner(ctx, req.SeriesId); err != nil {
+		return nil, err
+	}
+	return controllers.CancelReservationSeriesHandler(ctx, req)
 }
 
 // Implementación de los métodos del servicio de mesas
 func (s *Server) CreateTable(ctx context.Context, req *pb.CreateTableRequest) (*pb.Response, error) {
-	return controllers.CreateTableHandler(req)
+	return controllers.CreateTableHandler(ctx, req)
 }
 
-func (s *Server) GetTables(ctx context.Context, req *pb.Empty) (*pb.Tables, error) {
-	return controllers.GetTablesHandler(req)
+func (s *Server) GetTables(ctx context.Context, req *pb.GetTablesRequest) (*pb.Tables, error) {
+	return controllers.GetTablesHandler(ctx, req)
 }
 
 func (s *Server) UpdateTable(ctx context.Context, req *pb.UpdateTableRequest) (*pb.Response, error) {
-	return controllers.UpdateTableHandler(req)
+	return controllers.UpdateTableHandler(ctx, req)
 }
 
 func (s *Server) GetAvailableTables(ctx context.Context, req *pb.GetAvailableTablesRequest) (*pb.Tables, error) {
-	return controllers.GetAvailableTablesHandler(req)
+	return controllers.GetAvailableTablesHandler(ctx, req)
+}
+
+// Implementación de los métodos de la lista de espera
+func (s *Server) JoinWaitlist(ctx context.Context, req *pb.JoinWaitlistRequest) (*pb.WaitlistEntry, error) {
+	userID, err := ownUserID(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+	req.UserId = userID
+	return controllers.JoinWaitlistHandler(ctx, req)
+}
+
+func (s *Server) GetWaitlist(ctx context.Context, req *pb.GetWaitlistRequest) (*pb.WaitlistEntries, error) {
+	return controllers.GetWaitlistHandler(ctx, req)
+}
+
+func (s *Server) AcceptWaitlistOffer(ctx context.Context, req *pb.WaitlistEntryRequest) (*pb.Response, error) {
+	if err := requireWaitlistEntryOwner(ctx, req.RestaurantId, req.Id); err != nil {
+		return nil, err
+	}
+	return controllers.AcceptWaitlistOfferHandler(ctx, req)
+}
+
+func (s *Server) LeaveWaitlist(ctx context.Context, req *pb.WaitlistEntryRequest) (*pb.Response, error) {
+	if err := requireWaitlistEntryOwner(ctx, req.RestaurantId, req.Id); err != nil {
+		return nil, err
+	}
+	return controllers.LeaveWaitlistHandler(ctx, req)
+}
+
+// Implementación de los métodos del servicio de restaurantes
+func (s *Server) CreateRestaurant(ctx context.Context, req *pb.CreateRestaurantRequest) (*pb.Response, error) {
+	return controllers.CreateRestaurantHandler(ctx, req)
+}
+
+func (s *Server) GetRestaurants(ctx context.Context, req *pb.Empty) (*pb.Restaurants, error) {
+	return controllers.GetRestaurantsHandler(ctx, req)
+}
+
+func (s *Server) GetRestaurantByID(ctx context.Context, req *pb.GetRestaurantByIDRequest) (*pb.Restaurant, error) {
+	return controllers.GetRestaurantByIDHandler(ctx, req)
+}
+
+func (s *Server) UpdateRestaurant(ctx context.Context, req *pb.UpdateRestaurantRequest) (*pb.Response, error) {
+	return controllers.UpdateRestaurantHandler(ctx, req)
+}
+
+// Implementación de los métodos del servicio de auditoría
+func (s *Server) GetAuditHistory(ctx context.Context, req *pb.GetAuditHistoryRequest) (*pb.AuditHistory, error) {
+	return controllers.GetAuditHistoryHandler(ctx, req)
+}
+
+// Implementación de los métodos del servicio de webhooks
+func (s *Server) RegisterWebhook(ctx context.Context, req *pb.RegisterWebhookRequest) (*pb.Webhook, error) {
+	// Un webhook sin restaurante recibe los eventos de todos
+	if req.RestaurantId == "" {
+		if err := requireAdmin(ctx, "register webhooks for every restaurant"); err != nil {
+			return nil, err
+		}
+	}
+	return controllers.RegisterWebhookHandler(ctx, req)
+}
+
+func (s *Server) ListWebhooks(ctx context.Context, req *pb.ListWebhooksRequest) (*pb.Webhooks, error) {
+	return controllers.ListWebhooksHandler(ctx, req)
+}
+
+func (s *Server) DeleteWebhook(ctx context.Context, req *pb.WebhookRequest) (*pb.Response, error) {
+	return controllers.DeleteWebhookHandler(ctx, req)
+}
+
+func (s *Server) GetWebhookDeliveries(ctx context.Context, req *pb.GetWebhookDeliveriesRequest) (*pb.WebhookDeliveries, error) {
+	return controllers.GetWebhookDeliveriesHandler(ctx, req)
 }
@@ -0,0 +1,95 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"ms-reservas/logging"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// maxRequestIDLength limita el identificador aceptado del cliente para no
+// arrastrar valores arbitrariamente largos a los logs.
+const maxRequestIDLength = 128
+
+// LoggingUnaryInterceptor asigna un identificador a cada petición, lo
+// devuelve en la cabecera x-request-id y registra el resultado de la
+// llamada. Debe ser el primer interceptor de la cadena.
+func LoggingUnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx = withRequestID(ctx)
+		if err := grpc.SetHeader(ctx, metadata.Pairs(logging.RequestIDHeader, logging.RequestID(ctx))); err != nil {
+			slog.WarnContext(ctx, "failed to set request id header", "error", err)
+		}
+
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		logCall(ctx, info.FullMethod, start, err)
+		return resp, err
+	}
+}
+
+// LoggingStreamInterceptor es el equivalente de LoggingUnaryInterceptor
+// para llamadas en streaming.
+func LoggingStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := withRequestID(ss.Context())
+		if err := ss.SetHeader(metadata.Pairs(logging.RequestIDHeader, logging.RequestID(ctx))); err != nil {
+			slog.WarnContext(ctx, "failed to set request id header", "error", err)
+		}
+
+		start := time.Now()
+		err := handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+		logCall(ctx, info.FullMethod, start, err)
+		return err
+	}
+}
+
+// withRequestID reutiliza el x-request-id recibido o genera uno nuevo.
+func withRequestID(ctx context.Context) context.Context {
+	var requestID string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(logging.RequestIDHeader); len(values) > 0 {
+			requestID = values[0]
+		}
+	}
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		requestID = logging.NewRequestID()
+	}
+	return logging.NewContext(ctx, requestID)
+}
+
+func logCall(ctx context.Context, method string, start time.Time, err error) {
+	code := status.Code(err)
+	attrs := []slog.Attr{
+		slog.String("method", method),
+		slog.String("code", code.String()),
+		slog.Float64("duration_ms", float64(time.Since(start).Microseconds())/1000),
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		attrs = append(attrs, slog.String("peer", p.Addr.String()))
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", status.Convert(err).Message()))
+	}
+	slog.LogAttrs(ctx, callLevel(code), "rpc finished", attrs...)
+}
+
+// callLevel registra como error solo los fallos del servidor; los errores
+// atribuibles al cliente quedan como advertencias.
+func callLevel(code codes.Code) slog.Level {
+	switch code {
+	case codes.OK:
+		return slog.LevelInfo
+	case codes.Unknown, codes.Internal, codes.Unavailable, codes.DataLoss, codes.DeadlineExceeded, codes.Unimplemented:
+		return slog.LevelError
+	default:
+		return slog.LevelWarn
+	}
+}
@@ -0,0 +1,235 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"ms-reservas/auth"
+
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// Limit es un token bucket: Rate peticiones por segundo con ráfagas de
+// hasta Burst peticiones.
+type Limit struct {
+	Rate  float64 `json:"rate"`
+	Burst int     `json:"burst"`
+}
+
+// MethodLimits agrupa los límites que se aplican a un método: uno global
+// para todos los llamantes, uno por usuario autenticado y uno por IP.
+// Un límite ausente no se aplica.
+type MethodLimits struct {
+	Global  *Limit `json:"global,omitempty"`
+	PerUser *Limit `json:"per_user,omitempty"`
+	PerIP   *Limit `json:"per_ip,omitempty"`
+}
+
+// RateLimitConfig asigna límites a cada método; los no listados usan Default.
+type RateLimitConfig struct {
+	Default MethodLimits            `json:"default"`
+	Methods map[string]MethodLimits `json:"methods"`
+}
+
+// DefaultRateLimitConfig protege sobre todo la creación de reservas, la
+// consulta de disponibilidad, que recorre todas las reservas del día, y los
+// métodos públicos por código de confirmación frente a quien pruebe códigos.
+func DefaultRateLimitConfig() *RateLimitConfig {
+	return &RateLimitConfig{
+		Default: MethodLimits{
+			PerUser: &Limit{Rate: 10, Burst: 20},
+			PerIP:   &Limit{Rate: 20, Burst: 50},
+		},
+		Methods: map[string]MethodLimits{
+			"/reservation.ReservationService/CreateReservation": {
+				PerUser: &Limit{Rate: 0.5, Burst: 5},
+				PerIP:   &Limit{Rate: 2, Burst: 10},
+			},
+			"/reservation.ReservationService/GetReservationByCode": {
+				PerIP: &Limit{Rate: 0.2, Burst: 5},
+			},
+			"/reservation.ReservationService/UpdateReservationByCode": {
+				PerIP: &Limit{Rate: 0.2, Burst: 5},
+			},
+			"/reservation.ReservationService/CancelReservationByCode": {
+				PerIP: &Limit{Rate: 0.2, Burst: 5},
+			},
+			"/reservation.TableService/GetAvailableTables": {
+				Global:  &Limit{Rate: 50, Burst: 100},
+				PerUser: &Limit{Rate: 2, Burst: 10},
+				PerIP:   &Limit{Rate: 5, Burst: 20},
+			},
+		},
+	}
+}
+
+// LoadRateLimitConfig lee RATE_LIMIT_FILE (JSON) o devuelve la
+// configuración por defecto.
+func LoadRateLimitConfig() (*RateLimitConfig, error) {
+	path := os.Getenv("RATE_LIMIT_FILE")
+	if path == "" {
+		return DefaultRateLimitConfig(), nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rate limit file: %w", err)
+	}
+	var config RateLimitConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse rate limit file: %w", err)
+	}
+	return &config, nil
+}
+
+func (c *RateLimitConfig) limitsFor(method string) MethodLimits {
+	if limits, ok := c.Methods[method]; ok {
+		return limits
+	}
+	return c.Default
+}
+
+// RateLimiter mantiene un token bucket por método y clave (global, usuario
+// o IP). Los buckets sin uso se eliminan periódicamente.
+type RateLimiter struct {
+	config *RateLimitConfig
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+type bucket struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+const bucketIdleTimeout = 10 * time.Minute
+
+func NewRateLimiter(config *RateLimitConfig) *RateLimiter {
+	l := &RateLimiter{config: config, buckets: make(map[string]*bucket)}
+	go l.cleanup()
+	return l
+}
+
+func (l *RateLimiter) cleanup() {
+	ticker := time.NewTicker(bucketIdleTimeout)
+	defer ticker.Stop()
+	for range ticker.C {
+		l.mu.Lock()
+		for key, b := range l.buckets {
+			if time.Since(b.lastSeen) > bucketIdleTimeout {
+				delete(l.buckets, key)
+			}
+		}
+		l.mu.Unlock()
+	}
+}
+
+// allow consume un token del bucket y devuelve la reserva para poder
+// devolverlo; si no hay, devuelve cuánto falta para el siguiente.
+func (l *RateLimiter) allow(key string, limit *Limit, now time.Time) (*rate.Reservation, time.Duration) {
+	l.mu.Lock()
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{limiter: rate.NewLimiter(rate.Limit(limit.Rate), limit.Burst)}
+		l.buckets[key] = b
+	}
+	b.lastSeen = now
+	l.mu.Unlock()
+
+	reservation := b.limiter.ReserveN(now, 1)
+	if !reservation.OK() {
+		return nil, time.Second
+	}
+	if delay := reservation.DelayFrom(now); delay > 0 {
+		reservation.CancelAt(now)
+		return nil, delay
+	}
+	return reservation, 0
+}
+
+// check aplica los límites del método de más específico a más general: IP,
+// usuario y global. Si un límite rechaza la llamada se devuelven los tokens
+// ya consumidos, para que un cliente bloqueado no agote los buckets
+// compartidos con los demás.
+func (l *RateLimiter) check(ctx context.Context, method string) error {
+	limits := l.config.limitsFor(method)
+
+	type scope struct {
+		key   string
+		limit *Limit
+	}
+	var scopes []scope
+	if ip := peerIP(ctx); ip != "" {
+		scopes = append(scopes, scope{method + "|ip:" + ip, limits.PerIP})
+	}
+	if identity, ok := auth.FromContext(ctx); ok {
+		scopes = append(scopes, scope{method + "|user:" + identity.Subject, limits.PerUser})
+	}
+	scopes = append(scopes, scope{method + "|global", limits.Global})
+
+	// Reservar y devolver en el mismo instante permite recuperar el token
+	now := time.Now()
+	var taken []*rate.Reservation
+	for _, s := range scopes {
+		if s.limit == nil {
+			continue
+		}
+		reservation, wait := l.allow(s.key, s.limit, now)
+		if reservation == nil {
+			for _, r := range taken {
+				r.CancelAt(now)
+			}
+			retryAfter := strconv.Itoa(int(math.Ceil(wait.Seconds())))
+			grpc.SetHeader(ctx, metadata.Pairs("retry-after", retryAfter))
+			return status.Errorf(codes.ResourceExhausted, "rate limit exceeded for %s, retry after %ss", method, retryAfter)
+		}
+		taken = append(taken, reservation)
+	}
+	return nil
+}
+
+func peerIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
+
+// UnaryInterceptor devuelve codes.ResourceExhausted con la cabecera
+// retry-after (en segundos) cuando se supera algún límite. Debe ir después
+// de la autenticación para poder limitar por usuario.
+func (l *RateLimiter) UnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := l.check(ctx, info.FullMethod); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamInterceptor aplica los límites al abrir cada stream.
+func (l *RateLimiter) StreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := l.check(ss.Context(), info.FullMethod); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
@@ -0,0 +1,192 @@
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// CertReloader mantiene el certificado del servidor y las CA de clientes
+// cargados desde ficheros, y los recarga cuando cambian en disco sin
+// reiniciar el servidor.
+type CertReloader struct {
+	certFile string
+	keyFile  string
+	caFile   string
+
+	mu        sync.RWMutex
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+}
+
+// NewCertReloader carga el certificado, la clave y, si se indica, el
+// fichero de CA con el que se verifican los certificados de cliente.
+func NewCertReloader(certFile, keyFile, caFile string) (*CertReloader, error) {
+	r := &CertReloader{certFile: certFile, keyFile: keyFile, caFile: caFile}
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *CertReloader) reload() error {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load certificate: %w", err)
+	}
+
+	var pool *x509.CertPool
+	if r.caFile != "" {
+		pem, err := os.ReadFile(r.caFile)
+		if err != nil {
+			return fmt.Errorf("failed to read client CA file: %w", err)
+		}
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("client CA file has no valid certificates")
+		}
+	}
+
+	r.mu.Lock()
+	r.cert = &cert
+	r.clientCAs = pool
+	r.mu.Unlock()
+	return nil
+}
+
+// GetCertificate implementa tls.Config.GetCertificate.
+func (r *CertReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, nil
+}
+
+func (r *CertReloader) getClientCAs() *x509.CertPool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.clientCAs
+}
+
+// Watch vigila los directorios de los ficheros y recarga los certificados
+// al detectar cambios. Se vigilan los directorios y no los ficheros porque
+// muchas herramientas (y los secrets de Kubernetes) los sustituyen con un
+// renombrado. Si la recarga falla se mantienen los certificados anteriores.
+func (r *CertReloader) Watch() error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+
+	dirs := make(map[string]bool)
+	for _, file := range []string{r.certFile, r.keyFile, r.caFile} {
+		if file != "" {
+			dirs[filepath.Dir(file)] = true
+		}
+	}
+	for dir := range dirs {
+		if err := watcher.Add(dir); err != nil {
+			watcher.Close()
+			return err
+		}
+	}
+
+	go func() {
+		defer watcher.Close()
+		// Al renovar se suelen escribir varios ficheros seguidos: se espera
+		// a que dejen de llegar eventos antes de recargar.
+		var debounce <-chan time.Time
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if event.Has(fsnotify.Write) || event.Has(fsnotify.Create) || event.Has(fsnotify.Rename) || event.Has(fsnotify.Remove) {
+					debounce = time.After(500 * time.Millisecond)
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				slog.Error("certificate watcher error", "error", err)
+			case <-debounce:
+				if err := r.reload(); err != nil {
+					slog.Error("failed to reload TLS certificates, keeping previous ones", "error", err)
+					continue
+				}
+				slog.Info("TLS certificates reloaded")
+			}
+		}
+	}()
+	return nil
+}
+
+// TLSConfig devuelve la configuración TLS del servidor. Con clientAuth
+// distinto de tls.NoClientCert se verifican los certificados de cliente
+// contra la CA cargada (TLS mutuo).
+func (r *CertReloader) TLSConfig(clientAuth tls.ClientAuthType) *tls.Config {
+	base := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: r.GetCertificate,
+		ClientAuth:     clientAuth,
+	}
+	if clientAuth == tls.NoClientCert {
+		return base
+	}
+	// La CA se consulta en cada conexión para que una recarga tenga efecto
+	base.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		config := base.Clone()
+		config.GetConfigForClient = nil
+		config.ClientCAs = r.getClientCAs()
+		return config, nil
+	}
+	return base
+}
+
+// TLSConfigFromEnv construye la configuración TLS a partir de:
+//
+//	TLS_CERT_FILE       certificado del servidor (PEM)
+//	TLS_KEY_FILE        clave privada del servidor (PEM)
+//	TLS_CLIENT_CA_FILE  CA para verificar certificados de cliente (opcional)
+//	TLS_CLIENT_AUTH     "require" (por defecto si hay CA) u "optional"
+//
+// Devuelve nil si TLS no está configurado.
+func TLSConfigFromEnv() (*tls.Config, error) {
+	certFile := os.Getenv("TLS_CERT_FILE")
+	keyFile := os.Getenv("TLS_KEY_FILE")
+	if certFile == "" && keyFile == "" {
+		return nil, nil
+	}
+	if certFile == "" || keyFile == "" {
+		return nil, fmt.Errorf("both TLS_CERT_FILE and TLS_KEY_FILE are required")
+	}
+
+	caFile := os.Getenv("TLS_CLIENT_CA_FILE")
+	clientAuth := tls.NoClientCert
+	if caFile != "" {
+		switch os.Getenv("TLS_CLIENT_AUTH") {
+		case "", "require":
+			clientAuth = tls.RequireAndVerifyClientCert
+		case "optional":
+			clientAuth = tls.VerifyClientCertIfGiven
+		default:
+			return nil, fmt.Errorf("invalid TLS_CLIENT_AUTH, expected require or optional")
+		}
+	}
+
+	reloader, err := NewCertReloader(certFile, keyFile, caFile)
+	if err != nil {
+		return nil, err
+	}
+	if err := reloader.Watch(); err != nil {
+		return nil, fmt.Errorf("failed to watch certificate files: %w", err)
+	}
+	return reloader.TLSConfig(clientAuth), nil
+}
@@ -0,0 +1,56 @@
+package tracing
+
+import (
+	"context"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/event"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type commandKey struct {
+	connectionID string
+	requestID    int64
+}
+
+// CommandMonitor devuelve un monitor para options.Client().SetMonitor que
+// abre un span por cada comando enviado a Mongo. No se registra el texto de
+// la consulta para no volcar datos de clientes en las trazas.
+func CommandMonitor() *event.CommandMonitor {
+	var spans sync.Map
+
+	take := func(connectionID string, requestID int64) trace.Span {
+		span, ok := spans.LoadAndDelete(commandKey{connectionID, requestID})
+		if !ok {
+			return nil
+		}
+		return span.(trace.Span)
+	}
+
+	return &event.CommandMonitor{
+		Started: func(ctx context.Context, e *event.CommandStartedEvent) {
+			name := e.CommandName
+			attrs := []attribute.KeyValue{semconv.DBSystemMongoDB, semconv.DBNamespace(e.DatabaseName), semconv.DBOperationName(e.CommandName)}
+			if collection, ok := e.Command.Lookup(e.CommandName).StringValueOK(); ok {
+				name += " " + collection
+				attrs = append(attrs, semconv.DBCollectionName(collection))
+			}
+			_, span := tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+			spans.Store(commandKey{e.ConnectionID, e.RequestID}, span)
+		},
+		Succeeded: func(_ context.Context, e *event.CommandSucceededEvent) {
+			if span := take(e.ConnectionID, e.RequestID); span != nil {
+				span.End()
+			}
+		},
+		Failed: func(_ context.Context, e *event.CommandFailedEvent) {
+			if span := take(e.ConnectionID, e.RequestID); span != nil {
+				span.SetStatus(otelcodes.Error, e.Failure)
+				span.End()
+			}
+		},
+	}
+}
@@ -0,0 +1,116 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ServiceName es el nombre con el que el servicio aparece en las trazas si
+// no se define OTEL_SERVICE_NAME.
+const ServiceName = "ms-reservas"
+
+// DefaultTracesFile es el fichero del exportador "file" si no se define
+// OTEL_TRACES_FILE.
+const DefaultTracesFile = "traces.jsonl"
+
+var tracer = otel.Tracer("ms-reservas")
+
+// Setup configura el proveedor global de trazas según OTEL_TRACES_EXPORTER:
+//   - otlp: envía por gRPC al colector de OTEL_EXPORTER_OTLP_ENDPOINT.
+//   - stdout: escribe las trazas en la salida estándar.
+//   - file: las añade como JSON al fichero OTEL_TRACES_FILE.
+//   - none o vacío: no se exporta nada.
+//
+// La propagación W3C (traceparent y baggage) se activa siempre, para no
+// cortar las trazas de quien nos llama. La función devuelta vacía los spans
+// pendientes y debe llamarse al salir.
+func Setup(ctx context.Context) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	exporter, closeExporter, err := newExporter(ctx, strings.ToLower(os.Getenv("OTEL_TRACES_EXPORTER")))
+	if err != nil {
+		return nil, err
+	}
+	if exporter == nil {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	res, err := resource.New(ctx,
+		resource.WithAttributes(semconv.ServiceName(ServiceName)),
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+		resource.WithHost(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build trace resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+
+	return func(ctx context.Context) error {
+		err := provider.Shutdown(ctx)
+		if closeExporter != nil {
+			if closeErr := closeExporter(); err == nil {
+				err = closeErr
+			}
+		}
+		return err
+	}, nil
+}
+
+func newExporter(ctx context.Context, name string) (sdktrace.SpanExporter, func() error, error) {
+	switch name {
+	case "", "none":
+		return nil, nil, nil
+	case "otlp":
+		exporter, err := otlptracegrpc.New(ctx)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+		}
+		return exporter, nil, nil
+	case "stdout":
+		exporter, err := stdouttrace.New(stdouttrace.WithPrettyPrint())
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create stdout exporter: %w", err)
+		}
+		return exporter, nil, nil
+	case "file":
+		path := os.Getenv("OTEL_TRACES_FILE")
+		if path == "" {
+			path = DefaultTracesFile
+		}
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to open traces file: %w", err)
+		}
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(file))
+		if err != nil {
+			file.Close()
+			return nil, nil, fmt.Errorf("failed to create file exporter: %w", err)
+		}
+		return exporter, file.Close, nil
+	default:
+		return nil, nil, fmt.Errorf("invalid OTEL_TRACES_EXPORTER %q, expected otlp, stdout, file or none", name)
+	}
+}
+
+// Start abre un span hijo del que lleve ctx.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+}
@@ -0,0 +1,161 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// Cabeceras enviadas con cada entrega
+const (
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderEventID   = "X-Webhook-Event-Id"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// DefaultTimeout es el tiempo máximo que se espera la respuesta del
+// receptor.
+const DefaultTimeout = 10 * time.Second
+
+// Sign firma el cuerpo con HMAC-SHA256. Se firma "timestamp.cuerpo" para que
+// el receptor pueda rechazar entregas antiguas reenviadas por un tercero.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify comprueba una firma generada con Sign. La usan los receptores para
+// validar las entregas.
+func Verify(secret string, timestamp int64, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
+
+// ErrForbiddenAddress indica que el endpoint apunta a una dirección interna
+// (loopback, red privada, link-local...), a la que no se envían webhooks.
+var ErrForbiddenAddress = errors.New("webhook url must resolve to a public address")
+
+// publicIP indica si ip es una dirección pública a la que se pueden enviar
+// webhooks. Se excluyen las internas para que un endpoint registrado no
+// sirva para alcanzar servicios de la red del servidor, como el de
+// metadatos de la nube (169.254.169.254).
+func publicIP(ip net.IP) bool {
+	return ip != nil &&
+		!ip.IsLoopback() &&
+		!ip.IsPrivate() &&
+		!ip.IsLinkLocalUnicast() &&
+		!ip.IsLinkLocalMulticast() &&
+		!ip.IsInterfaceLocalMulticast() &&
+		!ip.IsMulticast() &&
+		!ip.IsUnspecified()
+}
+
+// ValidateURL exige una URL http o https absoluta cuyo host resuelva solo a
+// direcciones públicas.
+func ValidateURL(ctx context.Context, raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid webhook url, expected an absolute http or https url")
+	}
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, u.Hostname())
+	if err != nil {
+		return fmt.Errorf("failed to resolve webhook host: %w", err)
+	}
+	for _, addr := range addrs {
+		if !publicIP(addr.IP) {
+			return ErrForbiddenAddress
+		}
+	}
+	return nil
+}
+
+// NewClient crea el cliente HTTP de las entregas. Comprueba cada dirección
+// justo antes de conectar, de modo que un DNS que cambie después del
+// registro (o una redirección) tampoco lleve a una dirección interna.
+func NewClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if !publicIP(net.ParseIP(host)) {
+				return ErrForbiddenAddress
+			}
+			return nil
+		},
+	}
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: timeout,
+			MaxIdleConns:        100,
+			IdleConnTimeout:     90 * time.Second,
+		},
+	}
+}
+
+// Request es una entrega concreta a un endpoint.
+type Request struct {
+	URL       string
+	Secret    string
+	EventType string
+	EventID   string
+	Body      []byte
+}
+
+// Deliver envía la petición firmada y devuelve el código HTTP recibido. Solo
+// las respuestas 2xx cuentan como entregadas; cualquier otra devuelve error.
+func Deliver(ctx context.Context, client *http.Client, r Request) (int, error) {
+	timestamp := time.Now().Unix()
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, r.URL, bytes.NewReader(r.Body))
+	if err != nil {
+		return 0, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set(HeaderEvent, r.EventType)
+	httpReq.Header.Set(HeaderEventID, r.EventID)
+	httpReq.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	httpReq.Header.Set(HeaderSignature, Sign(r.Secret, timestamp, r.Body))
+
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("webhook endpoint responded %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// Backoff devuelve la espera antes del siguiente intento tras attempts
+// fallos: base, 2·base, 4·base... hasta max.
+func Backoff(attempts int, base, max time.Duration) time.Duration {
+	delay := base
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= max {
+			return max
+		}
+	}
+	return delay
+}
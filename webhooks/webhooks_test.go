@@ -0,0 +1,103 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func TestDeliver(t *testing.T) {
+	const secret = "s3cret"
+	body := []byte(`{"type":"reservation.created"}`)
+
+	var verified bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("failed to read body: %v", err)
+		}
+		timestamp, err := strconv.ParseInt(r.Header.Get(HeaderTimestamp), 10, 64)
+		if err != nil {
+			t.Errorf("invalid timestamp header: %v", err)
+		}
+		verified = Verify(secret, timestamp, received, r.Header.Get(HeaderSignature))
+		if r.Header.Get(HeaderEvent) != "reservation.created" || r.Header.Get(HeaderEventID) != "evt-1" {
+			t.Errorf("unexpected event headers: %v", r.Header)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	// El servidor de prueba escucha en loopback, así que se usa su cliente y
+	// no el de NewClient
+	status, err := Deliver(context.Background(), server.Client(), Request{
+		URL:       server.URL,
+		Secret:    secret,
+		EventType: "reservation.created",
+		EventID:   "evt-1",
+		Body:      body,
+	})
+	if err != nil || status != http.StatusNoContent {
+		t.Fatalf("Deliver() = %d, %v, want %d", status, err, http.StatusNoContent)
+	}
+	if !verified {
+		t.Error("the receiver could not verify the signature")
+	}
+	if Verify("other", 1, body, Sign(secret, 1, body)) {
+		t.Error("Verify() accepted a signature made with another secret")
+	}
+}
+
+func TestDeliverRejected(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	status, err := Deliver(context.Background(), server.Client(), Request{URL: server.URL})
+	if err == nil || status != http.StatusInternalServerError {
+		t.Fatalf("Deliver() = %d, %v, want %d and an error", status, err, http.StatusInternalServerError)
+	}
+}
+
+func TestNewClientRejectsInternalAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("the request should not reach a loopback address")
+	}))
+	defer server.Close()
+
+	_, err := Deliver(context.Background(), NewClient(DefaultTimeout), Request{URL: server.URL})
+	if !errors.Is(err, ErrForbiddenAddress) {
+		t.Fatalf("Deliver() error = %v, want %v", err, ErrForbiddenAddress)
+	}
+}
+
+func TestValidateURL(t *testing.T) {
+	tests := []struct {
+		url     string
+		wantErr bool
+	}{
+		{"ftp://example.com/hook", true},
+		{"/hook", true},
+		{"http://127.0.0.1:8080/hook", true},
+		{"http://localhost/hook", true},
+		{"http://10.0.0.5/hook", true},
+		{"http://192.168.1.10/hook", true},
+		{"http://169.254.169.254/latest/meta-data", true},
+		{"http://[::1]/hook", true},
+		{"http://0.0.0.0/hook", true},
+		{"https://93.184.216.34/hook", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			err := ValidateURL(context.Background(), tt.url)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateURL(%q) error = %v, wantErr %v", tt.url, err, tt.wantErr)
+			}
+		})
+	}
+}